@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// maintainersPaths are the paths of the MAINTAINERS file looked for in a
+// repository by the action command, in order.
+var maintainersPaths = []string{"MAINTAINERS", ".github/MAINTAINERS", "docs/MAINTAINERS"}
+
+// annotation is a problem found by the action command, reported as a GitHub
+// Actions workflow command. Level is "error", "warning" or "notice", and
+// Line is 0 if the problem is not on a given line.
+type annotation struct {
+	Level   string
+	Line    int
+	Message string
+}
+
+// actionCmd implements the action command.
+func actionCmd(args []string) error {
+	fs := flag.NewFlagSet("action", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: action\n\n"+
+			"Validates the MAINTAINERS file of the repository checked out by a GitHub Actions workflow against\n"+
+			"the schema of MAINTAINERS files and the combined maintainers, and reports the problems as annotations.\n"+
+			"It fails if any error is found. It takes no options but the following environment variables:\n\n"+
+			"  GITHUB_WORKSPACE   the checked out repository (default: the current directory)\n"+
+			"  GITHUB_REPOSITORY  the org/project of the repository, to find it in the combined maintainers\n"+
+			"  INPUT_PATH         the MAINTAINERS file in the repository (default: the first of %s)\n"+
+			"  INPUT_ROSTER       the URL or path of the combined maintainers, as TOML or JSON\n"+
+			"                     (default: the MAINTAINERS file of docker/opensource)\n",
+			strings.Join(maintainersPaths, ", "))
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("action: takes no arguments, it is set up with environment variables")
+	}
+
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		workspace = "."
+	}
+	path, err := findMaintainersFile(workspace, os.Getenv("INPUT_PATH"))
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(filepath.Join(workspace, path))
+	if err != nil {
+		return err
+	}
+
+	_, project := getProjectOrg(os.Getenv("GITHUB_REPOSITORY"))
+	ref := os.Getenv("INPUT_ROSTER")
+	if ref == "" {
+		ref = ghRawUri + "/docker/opensource/master/MAINTAINERS"
+	}
+	roster, err := loadRoster(workspace, ref)
+	var annotations []annotation
+	if err != nil {
+		annotations = append(annotations, annotation{Level: "warning", Message: fmt.Sprintf("loading the combined maintainers failed, only the schema was checked: %v", err)})
+	}
+	annotations = append(annotations, validateMaintainersFile(normalizeText(content), project, roster)...)
+
+	errors := 0
+	for _, a := range annotations {
+		printAnnotation(os.Stdout, path, a)
+		if a.Level == "error" {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("action: %s is invalid, see the errors above", path)
+	}
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
+
+// findMaintainersFile returns the path of the MAINTAINERS file in
+// workspace: the given one, or else the first of maintainersPaths found.
+func findMaintainersFile(workspace, path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	for _, p := range maintainersPaths {
+		if _, err := os.Stat(filepath.Join(workspace, p)); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("action: no MAINTAINERS file found in %s, set INPUT_PATH", workspace)
+}
+
+// loadRoster loads the combined maintainers from a URL, or a path relative to
+// workspace. JSON is expected if the name ends with .json, TOML otherwise.
+func loadRoster(workspace, ref string) (*Maintainers, error) {
+	var b []byte
+	var err error
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		var resp *http.Response
+		if resp, err = http.Get(ref); err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s failed: %s", ref, resp.Status)
+		}
+		b, err = ioutil.ReadAll(resp.Body)
+	} else {
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(workspace, ref)
+		}
+		b, err = ioutil.ReadFile(ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Maintainers
+	b = normalizeText(b)
+	if strings.HasSuffix(strings.SplitN(ref, "?", 2)[0], ".json") {
+		err = json.Unmarshal(b, &m)
+	} else {
+		_, err = toml.Decode(string(b), &m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s failed: %v", ref, err)
+	}
+	return &m, nil
+}
+
+// validateMaintainersFile checks the content of the MAINTAINERS file of a
+// project against the schema of MAINTAINERS files and, if not nil, the
+// combined maintainers.
+func validateMaintainersFile(content []byte, project string, roster *Maintainers) []annotation {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		a := annotation{Level: "error", Message: fmt.Sprintf("parsing failed: %v", err)}
+		if m := regexp.MustCompile(`line (\d+)`).FindStringSubmatch(err.Error()); m != nil {
+			a.Line, _ = strconv.Atoi(m[1])
+		}
+		return []annotation{a}
+	}
+
+	var annotations []annotation
+	add := func(level string, line int, format string, args ...interface{}) {
+		annotations = append(annotations, annotation{Level: level, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, p := range checkSchema("", reflect.TypeOf(MaintainersDepreciated{}), raw) {
+		key := strings.SplitN(p, ": ", 2)[0]
+		add("error", keyLine(content, key), "%s", p)
+	}
+	if len(annotations) > 0 {
+		return annotations
+	}
+
+	var m MaintainersDepreciated
+	if _, err := toml.Decode(string(content), &m); err != nil {
+		add("error", 0, "parsing failed: %v", err)
+		return annotations
+	}
+	section, maintainers := maintainersSection(m)
+	if section == "" {
+		add("error", 0, "no [Org.\"Core maintainers\"] or [Org.Maintainers] section")
+		return annotations
+	}
+	if len(maintainers) == 0 {
+		add("error", keyLine(content, "Org."+section), "no maintainers listed in [Org.%q]", section)
+	}
+
+	people := map[string]Person{}
+	for nick, p := range m.People {
+		people[strings.ToLower(nick)] = p
+	}
+	for _, nick := range maintainers {
+		p, ok := people[strings.ToLower(nick)]
+		switch {
+		case !ok:
+			add("error", quotedLine(content, nick), "%s is a maintainer without a [People.%s] entry", nick, nick)
+		case p.GitHub == "":
+			add("error", keyLine(content, "People."+nick), "[People.%s] has no GitHub handle", nick)
+		case p.Name == "" || p.Email == "":
+			add("warning", keyLine(content, "People."+nick), "[People.%s] has no Name or Email", nick)
+		}
+	}
+
+	if roster == nil {
+		return annotations
+	}
+	var nicks []string
+	for nick := range m.People {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+	for _, nick := range nicks {
+		p := m.People[nick]
+		line := keyLine(content, "People."+nick)
+		if r, ok := roster.People[strings.ToLower(nick)]; ok {
+			if p.GitHub != "" && r.GitHub != "" && !strings.EqualFold(p.GitHub, r.GitHub) {
+				add("warning", line, "%s is GitHub user %s here, but %s in the combined maintainers", nick, p.GitHub, r.GitHub)
+			}
+			if p.Email != "" && r.Email != "" && !strings.EqualFold(p.Email, r.Email) {
+				add("warning", line, "%s has the email %s here, but %s in the combined maintainers", nick, p.Email, r.Email)
+			}
+			continue
+		}
+		for other, r := range roster.People {
+			if p.GitHub != "" && strings.EqualFold(p.GitHub, r.GitHub) {
+				add("warning", line, "GitHub user %s is %s here, but %s in the combined maintainers", p.GitHub, nick, other)
+				break
+			}
+		}
+	}
+	if o, ok := roster.Org[sectionName(project)]; project != "" && ok {
+		added, removed := diffNicks(o.People, lowerAll(maintainers))
+		for _, nick := range added {
+			add("notice", quotedLine(content, nick), "%s is not yet a maintainer of %s in the combined maintainers", nick, project)
+		}
+		for _, nick := range removed {
+			add("notice", 0, "%s will no longer be a maintainer of %s in the combined maintainers", nick, project)
+		}
+	}
+	return annotations
+}
+
+// lowerAll returns the nicks lowercased, as in the combined maintainers.
+func lowerAll(nicks []string) []string {
+	lower := make([]string, len(nicks))
+	for i, n := range nicks {
+		lower[i] = strings.ToLower(n)
+	}
+	return lower
+}
+
+// keyLine returns the line of content defining the last part of a dotted key
+// as reported by checkSchema, or 0 if it is not found.
+func keyLine(content []byte, key string) int {
+	key = regexp.MustCompile(`\[\d+\]$`).ReplaceAllString(key, "")
+	parts := regexp.MustCompile(`"[^"]*"|[^.]+`).FindAllString(key, -1)
+	if len(parts) == 0 {
+		return 0
+	}
+	last := strings.ToLower(strings.Trim(parts[len(parts)-1], `"`))
+	for i, line := range strings.Split(string(content), "\n") {
+		t := strings.ToLower(strings.TrimSpace(line))
+		for _, name := range []string{last, `"` + last + `"`} {
+			if strings.HasPrefix(t, name+" ") || strings.HasPrefix(t, name+"=") ||
+				strings.HasSuffix(t, "."+name+"]") || t == "["+name+"]" {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// quotedLine returns the first line of content holding s as a quoted string,
+// or 0 if there is none.
+func quotedLine(content []byte, s string) int {
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(strings.ToLower(line), `"`+strings.ToLower(s)+`"`) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// printAnnotation writes a as a GitHub Actions workflow command on file.
+func printAnnotation(w io.Writer, file string, a annotation) {
+	escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	params := "file=" + property.Replace(file)
+	if a.Line > 0 {
+		params += ",line=" + strconv.Itoa(a.Line)
+	}
+	fmt.Fprintf(w, "::%s %s::%s\n", a.Level, params, escape.Replace(a.Message))
+}
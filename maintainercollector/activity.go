@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// activity holds the most recent contributions of a maintainer to a project.
+// Zero times mean no such activity was found.
+type activity struct {
+	LastCommit  time.Time
+	LastComment time.Time
+	LastReview  time.Time
+}
+
+// Last returns the most recent of all recorded activity.
+func (a activity) Last() time.Time {
+	last := a.LastCommit
+	for _, t := range []time.Time{a.LastComment, a.LastReview} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// getActivity looks up the last commit, issue or pull request comment and
+// pull request review of a GitHub user in a repository.
+func getActivity(org, project, handle string) (activity, error) {
+	var a activity
+
+	var commits []struct {
+		Commit struct {
+			Author struct {
+				Date time.Time `json:"date"`
+			} `json:"author"`
+		} `json:"commit"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/commits?author=%s&per_page=1", org, project, url.QueryEscape(handle)), &commits); err != nil {
+		return a, err
+	}
+	if len(commits) > 0 {
+		a.LastCommit = commits[0].Commit.Author.Date
+	}
+
+	var err error
+	if a.LastComment, err = lastOwnActivity(org, project, handle, "commenter:"+handle, "issues/%d/comments", "created_at"); err != nil {
+		return a, err
+	}
+	if a.LastReview, err = lastOwnActivity(org, project, handle, "type:pr reviewed-by:"+handle, "pulls/%d/reviews", "submitted_at"); err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
+
+// searchDepth is the number of issues and pull requests looked at to find
+// the last comment or review of a maintainer.
+const searchDepth = 20
+
+// lastOwnActivity returns the time of the last comment or review of handle
+// in the issues and pull requests of a repository matching qualifiers.
+// Search results are sorted by their last update, by anyone, so the time of
+// the activity of handle itself is read from the comments or reviews listed
+// at path, the time of each being its timeField.
+func lastOwnActivity(org, project, handle, qualifiers, path, timeField string) (time.Time, error) {
+	var result struct {
+		Items []struct {
+			Number    int       `json:"number"`
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"items"`
+	}
+	query := fmt.Sprintf("repo:%s/%s %s", org, project, qualifiers)
+	if err := githubGet(fmt.Sprintf("/search/issues?sort=updated&order=desc&per_page=%d&q=%s", searchDepth, url.QueryEscape(query)), &result); err != nil {
+		return time.Time{}, err
+	}
+
+	var last time.Time
+	for _, item := range result.Items {
+		// nothing on an item happened after its last update
+		if !item.UpdatedAt.After(last) {
+			break
+		}
+		for page := 1; ; page++ {
+			var entries []map[string]interface{}
+			if err := githubGet(fmt.Sprintf("/repos/%s/%s/"+path+"?per_page=100&page=%d", org, project, item.Number, page), &entries); err != nil {
+				return last, err
+			}
+			for _, e := range entries {
+				user, _ := e["user"].(map[string]interface{})
+				login, _ := user["login"].(string)
+				value, _ := e[timeField].(string)
+				t, err := time.Parse(time.RFC3339, value)
+				if err == nil && strings.EqualFold(login, handle) && t.After(last) {
+					last = t
+				}
+			}
+			if len(entries) < 100 {
+				break
+			}
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+)
+
+// compressArtifacts makes writeArtifact also write a gzip compressed copy of
+// each artifact, set with -gzip.
+var compressArtifacts bool
+
+// writeArtifact writes a generated artifact to name with the given encoding
+// and, if compressArtifacts is set, its compressed copy to name + ".gz".
+func writeArtifact(name string, data []byte, perm os.FileMode, enc Encoding) error {
+	data = enc.apply(data)
+	if err := ioutil.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	if !compressArtifacts {
+		return nil
+	}
+
+	gz, err := gzipBytes(data)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name+".gz", gz, perm)
+}
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// emailFinding describes a maintainer whose email address in People does not
+// match any author email of their recent commits to a project.
+type emailFinding struct {
+	Project string
+	Nick    string
+	Email   string
+	Seen    []string
+}
+
+// auditEmailsCmd implements the audit-emails command.
+func auditEmailsCmd(args []string) error {
+	fs := flag.NewFlagSet("audit-emails", flag.ExitOnError)
+	commits := fs.Int("commits", 30, "number of recent commits per maintainer to inspect")
+	fs.Parse(args)
+
+	findings := auditEmails(collectMaintainers(), *commits)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tMAINTAINER\tEMAIL\tCOMMIT EMAILS")
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Project, f.Nick, f.Email, strings.Join(f.Seen, ", "))
+	}
+	return w.Flush()
+}
+
+// auditEmails checks, for every maintainer of every project, whether the email
+// listed in People appears as author email in their recent commits to that
+// project. Maintainers without commits in the inspected range are skipped.
+func auditEmails(maintainers Maintainers, commits int) []emailFinding {
+	var findings []emailFinding
+
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		o, ok := maintainers.Org[sectionName(project)]
+		if !ok {
+			continue
+		}
+
+		for _, nick := range o.People {
+			person, ok := maintainers.People[nick]
+			if !ok {
+				logrus.Warnf("%s/%s: %s has no People entry", org, project, nick)
+				continue
+			}
+
+			handle := person.GitHub
+			if handle == "" {
+				handle = nick
+			}
+
+			emails, err := getCommitEmails(org, project, handle, commits)
+			if err != nil {
+				logrus.Errorf("%s/%s: fetching commits of %s failed: %v", org, project, handle, err)
+				continue
+			}
+			if len(emails) == 0 {
+				logrus.Debugf("%s/%s: no recent commits by %s", org, project, handle)
+				continue
+			}
+
+			if !containsFold(emails, person.Email) {
+				findings = append(findings, emailFinding{
+					Project: project,
+					Nick:    nick,
+					Email:   person.Email,
+					Seen:    emails,
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// getCommitEmails returns the distinct author emails of the last n commits
+// authored by the given GitHub user in a repository.
+func getCommitEmails(org, project, author string, n int) ([]string, error) {
+	var commits []struct {
+		Commit struct {
+			Author struct {
+				Email string `json:"email"`
+			} `json:"author"`
+		} `json:"commit"`
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/commits?author=%s&per_page=%d", org, project, url.QueryEscape(author), n)
+	if err := githubGet(path, &commits); err != nil {
+		return nil, err
+	}
+
+	var emails []string
+	for _, c := range commits {
+		if c.Commit.Author.Email != "" && !containsFold(emails, c.Commit.Author.Email) {
+			emails = append(emails, strings.ToLower(c.Commit.Author.Email))
+		}
+	}
+	sort.Strings(emails)
+	return emails, nil
+}
+
+// containsFold reports whether s is in slice, ignoring case.
+func containsFold(slice []string, s string) bool {
+	for _, e := range slice {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// Status of a release branch in the branches report.
+const (
+	branchInSync   = "in sync"
+	branchDiverged = "diverged"
+	branchNoFile   = "no MAINTAINERS"
+	branchUnparsed = "invalid MAINTAINERS"
+)
+
+// branchCoverage compares the maintainers listed on a release branch with
+// those of the default branch.
+type branchCoverage struct {
+	Org     string   `json:"org"`
+	Project string   `json:"project"`
+	Branch  string   `json:"branch"`
+	Status  string   `json:"status"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// branchesCmd implements the branches command.
+func branchesCmd(args []string) error {
+	fs := flag.NewFlagSet("branches", flag.ExitOnError)
+	pattern := fs.String("pattern", `^(release[/-]|v?[0-9]+\.[0-9]+)`, "regular expression matching the names of the release branches")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: branches [options] [project...]\n\n"+
+			"Reports the release branches whose MAINTAINERS file lists other maintainers than the default branch.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		return fmt.Errorf("branches: -pattern: %v", err)
+	}
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = projects
+	}
+
+	var report []branchCoverage
+	for _, p := range targets {
+		org, project := getProjectOrg(p)
+		c, err := releaseBranchCoverage(org, project, re)
+		if err != nil {
+			logrus.Errorf("%s/%s: %v", org, project, err)
+			continue
+		}
+		report = append(report, c...)
+	}
+
+	if *asJSON {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(b, '\n'))
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tBRANCH\tSTATUS\tADDED\tREMOVED")
+	for _, c := range report {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\n", c.Org, c.Project, c.Branch, c.Status, nickList(c.Added), nickList(c.Removed))
+	}
+	return w.Flush()
+}
+
+// releaseBranchCoverage compares the maintainers of each release branch of
+// org/project, those matching re, with the maintainers of its default
+// branch. Added are the maintainers of the branch missing from the default
+// branch, Removed those of the default branch missing from the branch.
+func releaseBranchCoverage(org, project string, re *regexp.Regexp) ([]branchCoverage, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s", org, project), &repo); err != nil {
+		return nil, err
+	}
+	_, want, err := branchMaintainers(org, project, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for page := 1; ; page++ {
+		var list []struct {
+			Name string `json:"name"`
+		}
+		if err := githubGet(fmt.Sprintf("/repos/%s/%s/branches?per_page=100&page=%d", org, project, page), &list); err != nil {
+			return nil, fmt.Errorf("listing branches failed: %v", err)
+		}
+		for _, b := range list {
+			if b.Name != repo.DefaultBranch && re.MatchString(b.Name) {
+				branches = append(branches, b.Name)
+			}
+		}
+		if len(list) < 100 {
+			break
+		}
+	}
+	sort.Strings(branches)
+
+	var coverage []branchCoverage
+	for _, branch := range branches {
+		c := branchCoverage{Org: org, Project: project, Branch: branch, Status: branchInSync}
+		status, people, err := branchMaintainers(org, project, branch)
+		switch {
+		case status != "":
+			c.Status = status
+			logrus.Debugf("%s/%s@%s: %v", org, project, branch, err)
+		case err != nil:
+			logrus.Errorf("%s/%s@%s: %v", org, project, branch, err)
+			continue
+		default:
+			c.Added, c.Removed = diffNicks(want, people)
+			if len(c.Added) > 0 || len(c.Removed) > 0 {
+				c.Status = branchDiverged
+			}
+		}
+		coverage = append(coverage, c)
+	}
+	return coverage, nil
+}
+
+// branchMaintainers returns the lowercased maintainers listed by the
+// MAINTAINERS file of a branch. If the file is missing or invalid, the
+// returned status says so, along with the error.
+func branchMaintainers(org, project, branch string) (string, []string, error) {
+	file, err := getBranchFile(org, project, "MAINTAINERS", branch)
+	if err != nil {
+		if errorClass(err) == classMissing {
+			return branchNoFile, nil, err
+		}
+		return "", nil, err
+	}
+	var m MaintainersDepreciated
+	if _, err := toml.Decode(string(file.Content), &m); err != nil {
+		return branchUnparsed, nil, fmt.Errorf("parsing MAINTAINERS failed: %v", err)
+	}
+	_, people := maintainersSection(m)
+	nicks := make([]string, len(people))
+	for i, n := range people {
+		nicks[i] = strings.ToLower(n)
+	}
+	return "", nicks, nil
+}
+
+// nickList formats nicks for a table cell.
+func nickList(nicks []string) string {
+	if len(nicks) == 0 {
+		return "-"
+	}
+	return strings.Join(nicks, ", ")
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const browseHelp = `Commands:
+    projects           list the projects
+    project <name|#>   show the maintainers of a project
+    person <nick|#>    show a person and the projects they maintain
+    search <text>      search people by nick, name, email or GitHub handle
+    audits             show the governance findings
+    help               show this help
+    quit               exit
+Numbers refer to the last list shown.
+`
+
+// browser is the state of an interactive browse session.
+type browser struct {
+	m        Maintainers
+	findings []finding
+	out      io.Writer
+
+	// last is the last list shown, numbered from 1.
+	last []string
+}
+
+// browseCmd implements the browse command.
+func browseCmd(args []string) error {
+	fs := flag.NewFlagSet("browse", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: browse\n\nExplores the combined maintainers interactively.\n\n%s", browseHelp)
+	}
+	fs.Parse(args)
+
+	c, err := runPipeline("audit")
+	if err != nil {
+		return err
+	}
+	b := &browser{m: c.Maintainers, findings: c.Findings, out: os.Stdout}
+	return b.run(os.Stdin)
+}
+
+// run reads commands from in until quit or the end of the input.
+func (b *browser) run(in io.Reader) error {
+	fmt.Fprintf(b.out, "%d projects, %d people, %d findings. Type help for the commands.\n",
+		len(b.m.Projects()), len(b.m.People), len(b.findings))
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(b.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(b.out)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		arg := strings.Join(fields[1:], " ")
+
+		switch strings.ToLower(fields[0]) {
+		case "projects", "p":
+			b.projects()
+		case "project":
+			b.project(b.resolve(arg))
+		case "person":
+			b.person(strings.ToLower(b.resolve(arg)))
+		case "search", "s", "/":
+			b.search(arg)
+		case "audits", "a":
+			b.audits()
+		case "help", "h", "?":
+			fmt.Fprint(b.out, browseHelp)
+		case "quit", "q", "exit":
+			return nil
+		default:
+			// a bare number or name drills into it
+			b.open(b.resolve(scanner.Text()))
+		}
+	}
+}
+
+// resolve returns the item of the last list numbered arg, or arg itself.
+func (b *browser) resolve(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= len(b.last) {
+		return b.last[n-1]
+	}
+	return arg
+}
+
+// open shows a project or a person, whichever name matches.
+func (b *browser) open(name string) {
+	if _, ok := b.m.Org[name]; ok {
+		b.project(name)
+		return
+	}
+	if _, ok := b.m.People[strings.ToLower(name)]; ok {
+		b.person(strings.ToLower(name))
+		return
+	}
+	fmt.Fprintf(b.out, "unknown command or name %q, type help for the commands\n", name)
+}
+
+func (b *browser) projects() {
+	w := tabwriter.NewWriter(b.out, 0, 8, 2, ' ', 0)
+	b.last = b.m.Projects()
+	for i, p := range b.last {
+		fmt.Fprintf(w, "%3d\t%s\t%d maintainers\n", i+1, p, len(b.m.Org[p].People))
+	}
+	w.Flush()
+}
+
+func (b *browser) project(name string) {
+	o, ok := b.m.Org[name]
+	if !ok {
+		fmt.Fprintf(b.out, "no project %q\n", name)
+		return
+	}
+
+	fmt.Fprintf(b.out, "%s\n", name)
+	w := tabwriter.NewWriter(b.out, 0, 8, 2, ' ', 0)
+	b.last = append([]string{}, o.People...)
+	for i, nick := range b.last {
+		p := b.m.People[nick]
+		fmt.Fprintf(w, "%3d\t%s\t%s\t%s\t@%s\n", i+1, nick, p.Name, p.Email, p.GitHub)
+	}
+	w.Flush()
+	for _, f := range b.findings {
+		if f.Project == name {
+			fmt.Fprintf(b.out, "  ! %s: %s\n", f.Kind, f.Message)
+		}
+	}
+}
+
+func (b *browser) person(nick string) {
+	p, ok := b.m.People[nick]
+	if !ok {
+		fmt.Fprintf(b.out, "no person %q\n", nick)
+		return
+	}
+
+	fmt.Fprintf(b.out, "%s\n    Name:   %s\n    Email:  %s\n    GitHub: @%s\n", nick, p.Name, p.Email, p.GitHub)
+	b.last = nil
+	for _, name := range b.m.Projects() {
+		if containsFold(b.m.Org[name].People, nick) {
+			b.last = append(b.last, name)
+		}
+	}
+	fmt.Fprintf(b.out, "Maintainer of:\n")
+	for i, name := range b.last {
+		fmt.Fprintf(b.out, "%3d  %s\n", i+1, name)
+	}
+}
+
+func (b *browser) search(text string) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		fmt.Fprintln(b.out, "usage: search <text>")
+		return
+	}
+
+	b.last = nil
+	for nick, p := range b.m.People {
+		for _, s := range []string{nick, p.Name, p.Email, p.GitHub} {
+			if strings.Contains(strings.ToLower(s), text) {
+				b.last = append(b.last, nick)
+				break
+			}
+		}
+	}
+	sort.Strings(b.last)
+	if len(b.last) == 0 {
+		fmt.Fprintln(b.out, "no match")
+		return
+	}
+	w := tabwriter.NewWriter(b.out, 0, 8, 2, ' ', 0)
+	for i, nick := range b.last {
+		p := b.m.People[nick]
+		fmt.Fprintf(w, "%3d\t%s\t%s\t%s\n", i+1, nick, p.Name, p.Email)
+	}
+	w.Flush()
+}
+
+func (b *browser) audits() {
+	if len(b.findings) == 0 {
+		fmt.Fprintln(b.out, "no findings")
+		return
+	}
+	w := tabwriter.NewWriter(b.out, 0, 8, 2, ' ', 0)
+	b.last = nil
+	for i, f := range b.findings {
+		b.last = append(b.last, f.Project)
+		fmt.Fprintf(w, "%3d\t%s\t%s\t%s\n", i+1, f.Kind, f.Project, f.Message)
+	}
+	w.Flush()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// cacheDir keeps a copy of the MAINTAINERS file of each project, set with
+// -cache. Combined with the blob SHAs recorded in the state file, only files
+// that changed since the last run are fetched again.
+var cacheDir string
+
+// useStale makes loadMaintainers fall back to the cached copy of a
+// MAINTAINERS file that fails to load, set with -stale.
+var useStale bool
+
+// parsedFragment is a parsed MAINTAINERS file and the SHA of its blob.
+type parsedFragment struct {
+	sha string
+	m   MaintainersDepreciated
+}
+
+// fragments keeps the parsed MAINTAINERS files of the cache, keyed by
+// "org/project", so that the regenerations of serve parse the files that
+// did not change only once. The parsed files are shared and must not be
+// modified.
+var fragments = struct {
+	sync.Mutex
+	m map[string]parsedFragment
+}{m: map[string]parsedFragment{}}
+
+// cachedFragment returns the parsed MAINTAINERS file of a project with the
+// blob SHA sha, from fragments or else from the cache file cached.
+func cachedFragment(org, project, cached, sha string) (MaintainersDepreciated, bool) {
+	fragments.Lock()
+	f, ok := fragments.m[org+"/"+project]
+	fragments.Unlock()
+	if ok && f.sha == sha {
+		return f.m, true
+	}
+
+	file, err := files.Load(cached)
+	if err != nil {
+		return MaintainersDepreciated{}, false
+	}
+	m, err := parseMaintainers(org, project, file)
+	if err != nil {
+		return m, false
+	}
+	keepFragment(org, project, sha, m)
+	return m, true
+}
+
+// keepFragment records the parsed MAINTAINERS file of a project in
+// fragments.
+func keepFragment(org, project, sha string, m MaintainersDepreciated) {
+	if sha == "" {
+		return
+	}
+	fragments.Lock()
+	fragments.m[org+"/"+project] = parsedFragment{sha: sha, m: m}
+	fragments.Unlock()
+}
+
+// loadMaintainers returns the MAINTAINERS file of a project, from the cache
+// if its blob SHA did not change since it was cached, and records the outcome
+// in the run state and report. If the file failed to load and its
+// last-known-good copy is returned instead, stale is the failure.
+func loadMaintainers(org, project string) (m MaintainersDepreciated, stale error, err error) {
+	var cached, sha, etag string
+	if cacheDir != "" {
+		cached = filepath.Join(cacheDir, org, project, "MAINTAINERS")
+
+		s, known := runState.project(org, project)
+		var err error
+		sha, etag, err = blobSha(org, project, "MAINTAINERS", s)
+		if err != nil {
+			logrus.Warnf("%s/%s: looking up the blob SHA of MAINTAINERS failed: %v", org, project, err)
+		} else if known && s.Sha == sha {
+			if m, ok := cachedFragment(org, project, cached, sha); ok {
+				logrus.Debugf("%s/%s: MAINTAINERS unchanged, using %s", org, project, cached)
+				runState.fetched(org, project, m, sha, etag)
+				report.project(org, project, statusCached, "", nil)
+				return m, nil, nil
+			}
+		}
+	}
+
+	m, source, err := fetchMaintainers(org, project, cached)
+	if err == nil {
+		keepFragment(org, project, sha, m)
+		runState.fetched(org, project, m, sha, etag)
+		report.project(org, project, statusFetched, source, nil)
+		return m, nil, nil
+	}
+
+	runState.failed(org, project, err)
+	if useStale && cached != "" {
+		if file, cerr := files.Load(cached); cerr == nil {
+			if m, cerr := parseMaintainers(org, project, file); cerr == nil {
+				logrus.Warnf("%v; using last-known-good copy %s", err, cached)
+				report.project(org, project, statusStale, "", err)
+				return m, err, nil
+			}
+		}
+	}
+
+	report.project(org, project, statusFailed, "", err)
+	return m, nil, err
+}
+
+// fetchMaintainers downloads and parses the MAINTAINERS file of a project,
+// and keeps a copy of it in the cache at cached, if not empty. It returns the
+// source the file was downloaded from.
+func fetchMaintainers(org, project, cached string) (MaintainersDepreciated, string, error) {
+	file, source, err := fetchWithSource(org, project, "MAINTAINERS")
+	if err != nil {
+		return MaintainersDepreciated{}, "", err
+	}
+	m, err := parseMaintainers(org, project, file)
+	if err != nil || cached == "" {
+		return m, source, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		logrus.Warnf("%s/%s: caching MAINTAINERS failed: %v", org, project, err)
+	} else if err := files.Save(cached, file, 0600); err != nil {
+		logrus.Warnf("%s/%s: caching MAINTAINERS failed: %v", org, project, err)
+	}
+
+	return m, source, nil
+}
+
+// blobSha returns the SHA of the blob of a file at the root of the master
+// branch of a repository, and the ETag of the tree listing it. If the state
+// of the project s is not nil, the tree is only listed if it changed since,
+// which does not count against the rate limit of GitHub.
+func blobSha(org, project, path string, s *projectState) (string, string, error) {
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Sha  string `json:"sha"`
+		} `json:"tree"`
+	}
+	etag := ""
+	if s != nil && s.Sha != "" {
+		etag = s.TreeETag
+	}
+	etag, notModified, err := githubGetIfNoneMatch(fmt.Sprintf("/repos/%s/%s/git/trees/master", org, project), etag, &tree)
+	if err != nil {
+		return "", "", err
+	}
+	if notModified {
+		return s.Sha, etag, nil
+	}
+
+	for _, e := range tree.Tree {
+		if e.Path == path {
+			return e.Sha, etag, nil
+		}
+	}
+	return "", "", fmt.Errorf("%s not found", path)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadMaintainersCached loads a MAINTAINERS file twice through the
+// cache: the second time, the tree is not modified and the file is neither
+// fetched nor parsed again.
+func TestLoadMaintainersCached(t *testing.T) {
+	file, err := ioutil.ReadFile(filepath.Join("testdata", "fixtures", "raw", "docker", "cli", "master", "MAINTAINERS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trees, notModified, raws int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/repos/docker/cli/git/trees/master":
+			trees++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"tree": [{"path": "MAINTAINERS", "sha": "abc"}]}`))
+		case "/raw/docker/cli/master/MAINTAINERS":
+			raws++
+			w.Write(file)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	savedRaw, savedAPI, savedCache, savedState := ghRawUri, ghApiUri, cacheDir, runState
+	ghRawUri, ghApiUri, cacheDir = srv.URL+"/raw", srv.URL+"/api", t.TempDir()
+	runState = &collectorState{Projects: map[string]*projectState{}}
+	t.Cleanup(func() { ghRawUri, ghApiUri, cacheDir, runState = savedRaw, savedAPI, savedCache, savedState })
+	useMocks(t, nil, rawFetcher{}, nil, nil)
+
+	for i := 0; i < 2; i++ {
+		m, _, err := loadMaintainers("docker", "cli")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, people := maintainersSection(m); len(people) == 0 {
+			t.Errorf("run %d: no maintainers loaded", i+1)
+		}
+	}
+	if trees != 2 || notModified != 1 || raws != 1 {
+		t.Errorf("got %d tree requests, %d not modified and %d downloads, want 2, 1 and 1", trees, notModified, raws)
+	}
+	if s, _ := runState.project("docker", "cli"); s.Sha != "abc" || s.TreeETag != `"v1"` {
+		t.Errorf("got state %+v, want the SHA and ETag of the tree", s)
+	}
+}
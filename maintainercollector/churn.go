@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// projectChurn is a row of the churn report.
+type projectChurn struct {
+	Project string `json:"project"`
+	quarterChurn
+}
+
+// churnCmd implements the churn command.
+func churnCmd(args []string) error {
+	fs := flag.NewFlagSet("churn", flag.ExitOnError)
+	from := fs.String("from", "history", "compute the churn from the history store given with -history, or from the git history of the MAINTAINERS files (git)")
+	format := fs.String("format", "csv", "output format, csv or json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: churn [options] [project...]\n\nReports the maintainers added to and removed from each project per quarter.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = projects
+	}
+
+	var rows []projectChurn
+	switch *from {
+	case "history":
+		if historyFile == "" {
+			return fmt.Errorf("churn: -from history requires -history")
+		}
+		history, err := loadHistory(historyFile)
+		if err != nil {
+			return err
+		}
+		// the snapshots are keyed by section, which aliased projects share
+		seen := map[string]bool{}
+		for _, p := range targets {
+			_, project := getProjectOrg(p)
+			section := sectionName(project)
+			if seen[section] {
+				continue
+			}
+			seen[section] = true
+			for _, c := range churnByQuarter(history, section) {
+				rows = append(rows, projectChurn{Project: section, quarterChurn: c})
+			}
+		}
+	case "git":
+		seen := map[string]bool{}
+		for _, p := range targets {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			org, project := getProjectOrg(p)
+			history, err := gitHistory(org, project)
+			if err != nil {
+				logrus.Errorf("%s/%s: %v", org, project, err)
+				continue
+			}
+			for _, c := range churnByQuarter(history, project) {
+				rows = append(rows, projectChurn{Project: project, quarterChurn: c})
+			}
+		}
+	default:
+		return fmt.Errorf("churn: unknown -from %q, expected history or git", *from)
+	}
+
+	switch *format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"project", "quarter", "added", "removed"})
+		for _, r := range rows {
+			w.Write([]string{r.Project, r.Quarter, strconv.Itoa(r.Added), strconv.Itoa(r.Removed)})
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		if rows == nil {
+			rows = []projectChurn{}
+		}
+		b, err := json.MarshalIndent(rows, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	default:
+		return fmt.Errorf("churn: unknown -format %q, expected csv or json", *format)
+	}
+}
+
+// gitHistory returns the maintainers of a project after each commit to its
+// MAINTAINERS file, as snapshots of that project only, oldest first.
+func gitHistory(org, project string) ([]snapshot, error) {
+	var commits []struct {
+		Sha    string `json:"sha"`
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/commits?path=MAINTAINERS&per_page=100", org, project), &commits); err != nil {
+		return nil, err
+	}
+
+	// commits are returned newest first
+	var history []snapshot
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		var content struct {
+			Content string `json:"content"`
+		}
+		if err := githubGet(fmt.Sprintf("/repos/%s/%s/contents/MAINTAINERS?ref=%s", org, project, c.Sha), &content); err != nil {
+			logrus.Warnf("%s/%s: MAINTAINERS at %s: %v", org, project, c.Sha, err)
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.Replace(content.Content, "\n", "", -1))
+		if err != nil {
+			logrus.Warnf("%s/%s: decoding MAINTAINERS at %s failed: %v", org, project, c.Sha, err)
+			continue
+		}
+
+		var m MaintainersDepreciated
+		if _, err := toml.Decode(string(normalizeText(b)), &m); err != nil {
+			logrus.Warnf("%s/%s: parsing MAINTAINERS at %s failed: %v", org, project, c.Sha, err)
+			continue
+		}
+		_, people := maintainersSection(m)
+		nicks := make([]string, len(people))
+		for i, n := range people {
+			nicks[i] = strings.ToLower(n)
+		}
+		history = append(history, snapshot{Time: c.Commit.Committer.Date, Projects: map[string][]string{project: nicks}})
+	}
+	return history, nil
+}
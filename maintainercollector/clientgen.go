@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// apiSpec is the part of the OpenAPI specification of openapi.json the
+// client generator reads.
+type apiSpec struct {
+	Paths      map[string]map[string]apiOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*apiSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type apiOperation struct {
+	OperationID string `json:"operationId"`
+	Summary     string `json:"summary"`
+	Parameters  []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *apiSchema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type apiSchema struct {
+	Ref                  string                `json:"$ref"`
+	Type                 string                `json:"type"`
+	Properties           map[string]*apiSchema `json:"properties"`
+	AdditionalProperties *apiSchema            `json:"additionalProperties"`
+	Items                *apiSchema            `json:"items"`
+}
+
+// apiMethod is an operation of the API, as a method of the clients.
+type apiMethod struct {
+	Name    string
+	Path    string
+	Summary string
+
+	// Params are the names of the query parameters, passed as arguments.
+	Params []string
+
+	// Result is the name of the schema of the JSON response, or empty if
+	// the response is returned as is.
+	Result string
+}
+
+// clientCmd implements the client command.
+func clientCmd(args []string) error {
+	fs := flag.NewFlagSet("client", flag.ExitOnError)
+	lang := fs.String("lang", "go", "language of the client, go or typescript")
+	pkg := fs.String("package", "maintainers", "package name of the Go client")
+	output := fs.String("o", "-", "write the client to this file, - for the standard output")
+	spec := fs.Bool("spec", false, "write the OpenAPI specification instead of a client")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: client [options]\n\nGenerates a client of the API of the serve command from its OpenAPI specification.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var out []byte
+	var err error
+	switch {
+	case *spec:
+		out = []byte(openapi)
+	case *lang == "go":
+		out, err = generateGoClient(*pkg)
+	case *lang == "typescript" || *lang == "ts":
+		out, err = generateTypeScriptClient()
+	default:
+		err = fmt.Errorf("client: unknown -lang %q, expected go or typescript", *lang)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	return ioutil.WriteFile(*output, out, 0644)
+}
+
+// parseSpec returns the embedded OpenAPI specification, with its GET
+// operations as methods sorted by path.
+func parseSpec() (apiSpec, []apiMethod, error) {
+	var spec apiSpec
+	if err := json.Unmarshal([]byte(openapi), &spec); err != nil {
+		return spec, nil, fmt.Errorf("parsing the OpenAPI specification failed: %v", err)
+	}
+
+	var paths []string
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var methods []apiMethod
+	for _, p := range paths {
+		op, ok := spec.Paths[p]["get"]
+		if !ok {
+			continue
+		}
+		m := apiMethod{Name: exportedName(op.OperationID), Path: p, Summary: op.Summary}
+		for _, param := range op.Parameters {
+			if param.In == "query" {
+				m.Params = append(m.Params, param.Name)
+			}
+		}
+		if c, ok := op.Responses["200"].Content["application/json"]; ok && c.Schema != nil && c.Schema.Ref != "" {
+			m.Result = refName(c.Schema.Ref)
+		}
+		methods = append(methods, m)
+	}
+	return spec, methods, nil
+}
+
+// schemaNames returns the sorted names of the schemas of spec.
+func schemaNames(spec apiSpec) []string {
+	var names []string
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// propertyNames returns the sorted names of the properties of s.
+func propertyNames(s *apiSchema) []string {
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// goType returns the Go type of a schema.
+func goType(s *apiSchema) string {
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "array":
+		return "[]" + goType(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map[string]" + goType(s.AdditionalProperties)
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float64"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "string":
+		return "string"
+	default:
+		return "interface{}"
+	}
+}
+
+// generateGoClient returns the source of a Go client package.
+func generateGoClient(pkg string) ([]byte, error) {
+	spec, methods, err := parseSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `// Code generated by maintainercollector client; DO NOT EDIT.
+
+// Package %s is a client of the API of the maintainers collector.
+package %s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// Client calls the API at BaseURL, including the tenant prefix if any.
+type Client struct {
+	BaseURL string
+
+	// Token, if set, is sent as a bearer token.
+	Token string
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+func (c *Client) get(path string, query url.Values) ([]byte, error) {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %%s: %%s", path, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+`, pkg, pkg)
+
+	for _, m := range methods {
+		fmt.Fprintf(buf, "\n// %s gets %s.\n// %s\n", m.Name, m.Path, m.Summary)
+		args, query := "", "nil"
+		if len(m.Params) > 0 {
+			args = strings.Join(m.Params, ", ") + " string"
+			var values []string
+			for _, p := range m.Params {
+				values = append(values, fmt.Sprintf("%q: {%s}", p, p))
+			}
+			query = "url.Values{" + strings.Join(values, ", ") + "}"
+		}
+		if m.Result == "" {
+			fmt.Fprintf(buf, "func (c *Client) %s(%s) ([]byte, error) {\n\treturn c.get(%q, %s)\n}\n", m.Name, args, m.Path, query)
+			continue
+		}
+		fmt.Fprintf(buf, `func (c *Client) %s(%s) (*%s, error) {
+	b, err := c.get(%q, %s)
+	if err != nil {
+		return nil, err
+	}
+	var v %s
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+`, m.Name, args, m.Result, m.Path, query, m.Result)
+	}
+
+	for _, name := range schemaNames(spec) {
+		s := spec.Components.Schemas[name]
+		fmt.Fprintf(buf, "\ntype %s struct {\n", name)
+		for _, p := range propertyNames(s) {
+			fmt.Fprintf(buf, "\t%s %s `json:\"%s,omitempty\"`\n", exportedName(p), goType(s.Properties[p]), p)
+		}
+		fmt.Fprintf(buf, "}\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the Go client failed: %v", err)
+	}
+	return src, nil
+}
+
+// tsType returns the TypeScript type of a schema.
+func tsType(s *apiSchema) string {
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "array":
+		return tsType(s.Items) + "[]"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "{ [key: string]: " + tsType(s.AdditionalProperties) + " }"
+	case s.Type == "integer" || s.Type == "number":
+		return "number"
+	case s.Type == "boolean":
+		return "boolean"
+	case s.Type == "string":
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// generateTypeScriptClient returns the source of a TypeScript client module.
+func generateTypeScriptClient() ([]byte, error) {
+	spec, methods, err := parseSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `// Code generated by maintainercollector client; DO NOT EDIT.
+`)
+	for _, name := range schemaNames(spec) {
+		s := spec.Components.Schemas[name]
+		fmt.Fprintf(buf, "\nexport interface %s {\n", name)
+		for _, p := range propertyNames(s) {
+			fmt.Fprintf(buf, "  %s?: %s;\n", p, tsType(s.Properties[p]))
+		}
+		fmt.Fprintf(buf, "}\n")
+	}
+
+	fmt.Fprintf(buf, `
+/** Client calls the API at baseURL, including the tenant prefix if any. */
+export class Client {
+  constructor(private baseURL: string, private token?: string) {}
+
+  private async get(path: string, query?: { [key: string]: string }): Promise<Response> {
+    if (query) {
+      path += "?" + new URLSearchParams(query).toString();
+    }
+    const headers: { [key: string]: string } = {};
+    if (this.token) {
+      headers["Authorization"] = "Bearer " + this.token;
+    }
+    const resp = await fetch(this.baseURL + path, { headers });
+    if (resp.status !== 200) {
+      throw new Error("GET " + path + ": " + resp.status + " " + resp.statusText);
+    }
+    return resp;
+  }
+`)
+	for _, m := range methods {
+		name := strings.ToLower(m.Name[:1]) + m.Name[1:]
+		fmt.Fprintf(buf, "\n  /** %s */\n", m.Summary)
+		var args []string
+		get := fmt.Sprintf("this.get(%q)", m.Path)
+		if len(m.Params) > 0 {
+			for _, p := range m.Params {
+				args = append(args, p+": string")
+			}
+			get = fmt.Sprintf("this.get(%q, { %s })", m.Path, strings.Join(m.Params, ", "))
+		}
+		if m.Result == "" {
+			fmt.Fprintf(buf, "  async %s(%s): Promise<string> {\n    return (await %s).text();\n  }\n", name, strings.Join(args, ", "), get)
+			continue
+		}
+		fmt.Fprintf(buf, "  async %s(%s): Promise<%s> {\n    return (await %s).json();\n  }\n", name, strings.Join(args, ", "), m.Result, get)
+	}
+	fmt.Fprintf(buf, "}\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+// Package clients defines the interfaces the maintainer collector talks to
+// the outside world through: the GitHub API, the repositories it fetches
+// files from, the webhooks it notifies and the store of its files. Programs
+// embedding the collector, and its own tests, replace them with the mocks
+// of package clientstest, generated from this file.
+package clients
+
+//go:generate go run genmocks.go
+
+import (
+	"os"
+)
+
+// GitHub sends requests to the GitHub API.
+type GitHub interface {
+	// Request sends a request to path and decodes the JSON response into v
+	// (if v is not nil). If body is not nil, it is sent JSON encoded.
+	Request(method string, path string, body interface{}, v interface{}) error
+}
+
+// Fetcher downloads files from repositories.
+type Fetcher interface {
+	// Fetch returns the file at path in the repository org/project.
+	Fetch(org string, project string, path string) ([]byte, error)
+}
+
+// Notifier delivers the webhooks.
+type Notifier interface {
+	// Notify posts payload to url, signed with secret if not empty.
+	Notify(url string, secret string, payload []byte) error
+}
+
+// Store persists the files of the collector holding personal data.
+type Store interface {
+	// Load reads the file at path. The error of a missing file satisfies
+	// os.IsNotExist.
+	Load(path string) ([]byte, error)
+
+	// Save writes data to the file at path, created with perm.
+	Save(path string, data []byte, perm os.FileMode) error
+}
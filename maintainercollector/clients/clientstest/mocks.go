@@ -0,0 +1,147 @@
+// Code generated by genmocks.go from clients.go. DO NOT EDIT.
+
+// Package clientstest provides mocks of the interfaces of package clients.
+// Each mock records its calls, and answers them with the function of the
+// method if set, or else with zero values. Mocks are safe for concurrent use;
+// their calls are read once they are no longer in use.
+package clientstest
+
+import (
+	"os"
+	"sync"
+)
+
+// GitHub is a mock of clients.GitHub.
+type GitHub struct {
+	RequestFunc  func(method string, path string, body interface{}, v interface{}) error
+	RequestCalls []GitHubRequestCall
+
+	mu sync.Mutex
+}
+
+// GitHubRequestCall is a call of GitHub.Request.
+type GitHubRequestCall struct {
+	Method string
+	Path   string
+	Body   interface{}
+	V      interface{}
+}
+
+// Request records the call, and calls RequestFunc if set.
+func (m *GitHub) Request(method string, path string, body interface{}, v interface{}) error {
+	m.mu.Lock()
+	m.RequestCalls = append(m.RequestCalls, GitHubRequestCall{method, path, body, v})
+	f := m.RequestFunc
+	m.mu.Unlock()
+	if f != nil {
+		return f(method, path, body, v)
+	}
+	var r0 error
+	return r0
+}
+
+// Fetcher is a mock of clients.Fetcher.
+type Fetcher struct {
+	FetchFunc  func(org string, project string, path string) ([]byte, error)
+	FetchCalls []FetcherFetchCall
+
+	mu sync.Mutex
+}
+
+// FetcherFetchCall is a call of Fetcher.Fetch.
+type FetcherFetchCall struct {
+	Org     string
+	Project string
+	Path    string
+}
+
+// Fetch records the call, and calls FetchFunc if set.
+func (m *Fetcher) Fetch(org string, project string, path string) ([]byte, error) {
+	m.mu.Lock()
+	m.FetchCalls = append(m.FetchCalls, FetcherFetchCall{org, project, path})
+	f := m.FetchFunc
+	m.mu.Unlock()
+	if f != nil {
+		return f(org, project, path)
+	}
+	var r0 []byte
+	var r1 error
+	return r0, r1
+}
+
+// Notifier is a mock of clients.Notifier.
+type Notifier struct {
+	NotifyFunc  func(url string, secret string, payload []byte) error
+	NotifyCalls []NotifierNotifyCall
+
+	mu sync.Mutex
+}
+
+// NotifierNotifyCall is a call of Notifier.Notify.
+type NotifierNotifyCall struct {
+	Url     string
+	Secret  string
+	Payload []byte
+}
+
+// Notify records the call, and calls NotifyFunc if set.
+func (m *Notifier) Notify(url string, secret string, payload []byte) error {
+	m.mu.Lock()
+	m.NotifyCalls = append(m.NotifyCalls, NotifierNotifyCall{url, secret, payload})
+	f := m.NotifyFunc
+	m.mu.Unlock()
+	if f != nil {
+		return f(url, secret, payload)
+	}
+	var r0 error
+	return r0
+}
+
+// Store is a mock of clients.Store.
+type Store struct {
+	LoadFunc  func(path string) ([]byte, error)
+	LoadCalls []StoreLoadCall
+	SaveFunc  func(path string, data []byte, perm os.FileMode) error
+	SaveCalls []StoreSaveCall
+
+	mu sync.Mutex
+}
+
+// StoreLoadCall is a call of Store.Load.
+type StoreLoadCall struct {
+	Path string
+}
+
+// Load records the call, and calls LoadFunc if set.
+func (m *Store) Load(path string) ([]byte, error) {
+	m.mu.Lock()
+	m.LoadCalls = append(m.LoadCalls, StoreLoadCall{path})
+	f := m.LoadFunc
+	m.mu.Unlock()
+	if f != nil {
+		return f(path)
+	}
+	var r0 []byte
+	var r1 error
+	return r0, r1
+}
+
+// StoreSaveCall is a call of Store.Save.
+type StoreSaveCall struct {
+	Path string
+	Data []byte
+	Perm os.FileMode
+}
+
+// Save records the call, and calls SaveFunc if set.
+func (m *Store) Save(path string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	m.SaveCalls = append(m.SaveCalls, StoreSaveCall{path, data, perm})
+	f := m.SaveFunc
+	m.mu.Unlock()
+	if f != nil {
+		return f(path, data, perm)
+	}
+	var r0 error
+	return r0
+}
@@ -0,0 +1,186 @@
+// +build ignore
+
+// genmocks writes clientstest/mocks.go, a mock of every interface of
+// clients.go. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "clients.go", nil, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+
+	body := new(bytes.Buffer)
+	for _, decl := range f.Decls {
+		g, ok := decl.(*ast.GenDecl)
+		if !ok || g.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range g.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+				if err := writeMock(body, fset, ts.Name.Name, iface); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+
+	// only the imports of clients.go used by the signatures are kept
+	imports := []string{`"sync"`}
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if strings.Contains(body.String(), name+".") {
+			imports = append(imports, spec.Path.Value)
+		}
+	}
+	sort.Strings(imports)
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "// Code generated by genmocks.go from clients.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "// Package clientstest provides mocks of the interfaces of package clients.\n")
+	fmt.Fprintf(out, "// Each mock records its calls, and answers them with the function of the\n")
+	fmt.Fprintf(out, "// method if set, or else with zero values. Mocks are safe for concurrent use;\n")
+	fmt.Fprintf(out, "// their calls are read once they are no longer in use.\n")
+	fmt.Fprintf(out, "package clientstest\n\nimport (\n\t%s\n)\n", strings.Join(imports, "\n\t"))
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		panic(fmt.Errorf("%v\n%s", err, out.Bytes()))
+	}
+	if err := ioutil.WriteFile(filepath.Join("clientstest", "mocks.go"), src, 0644); err != nil {
+		panic(err)
+	}
+}
+
+// param is a parameter or result of a method.
+type param struct {
+	name, typ string
+}
+
+// writeMock writes the mock of the interface name to w.
+func writeMock(w *bytes.Buffer, fset *token.FileSet, name string, iface *ast.InterfaceType) error {
+	fmt.Fprintf(w, "\n// %[1]s is a mock of clients.%[1]s.\ntype %[1]s struct {\n", name)
+	type method struct {
+		name            string
+		params, results []param
+	}
+	var methods []method
+	for _, m := range iface.Methods.List {
+		fn, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return fmt.Errorf("%s: only methods are supported, not embedded interfaces", name)
+		}
+		params, err := fields(fset, fn.Params, "p")
+		if err != nil {
+			return err
+		}
+		results, err := fields(fset, fn.Results, "r")
+		if err != nil {
+			return err
+		}
+		methods = append(methods, method{m.Names[0].Name, params, results})
+	}
+
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%sFunc func(%s) %s\n", m.name, list(m.params, true), resultList(m.results))
+		fmt.Fprintf(w, "\t%sCalls []%s%sCall\n", m.name, name, m.name)
+	}
+	fmt.Fprintf(w, "\n\tmu sync.Mutex\n}\n")
+
+	for _, m := range methods {
+		fmt.Fprintf(w, "\n// %s%sCall is a call of %s.%s.\ntype %s%sCall struct {\n", name, m.name, name, m.name, name, m.name)
+		for _, p := range m.params {
+			fmt.Fprintf(w, "\t%s %s\n", exported(p.name), p.typ)
+		}
+		fmt.Fprintf(w, "}\n")
+
+		fmt.Fprintf(w, "\n// %s records the call, and calls %sFunc if set.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (m *%s) %s(%s) %s {\n", name, m.name, list(m.params, true), resultList(m.results))
+		fmt.Fprintf(w, "\tm.mu.Lock()\n\tm.%sCalls = append(m.%sCalls, %s%sCall{%s})\n\tf := m.%sFunc\n\tm.mu.Unlock()\n",
+			m.name, m.name, name, m.name, list(m.params, false), m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\tif f != nil {\n\t\tf(%s)\n\t}\n}\n", list(m.params, false))
+			continue
+		}
+		fmt.Fprintf(w, "\tif f != nil {\n\t\treturn f(%s)\n\t}\n", list(m.params, false))
+		for _, r := range m.results {
+			fmt.Fprintf(w, "\tvar %s %s\n", r.name, r.typ)
+		}
+		fmt.Fprintf(w, "\treturn %s\n}\n", list(m.results, false))
+	}
+	return nil
+}
+
+// fields returns the parameters or results of a method, naming the unnamed
+// ones with prefix and their index.
+func fields(fset *token.FileSet, l *ast.FieldList, prefix string) ([]param, error) {
+	var params []param
+	if l == nil {
+		return nil, nil
+	}
+	for _, f := range l.List {
+		typ := new(bytes.Buffer)
+		if err := printer.Fprint(typ, fset, f.Type); err != nil {
+			return nil, err
+		}
+		if len(f.Names) == 0 {
+			params = append(params, param{fmt.Sprintf("%s%d", prefix, len(params)), typ.String()})
+		}
+		for _, n := range f.Names {
+			params = append(params, param{n.Name, typ.String()})
+		}
+	}
+	return params, nil
+}
+
+// list returns the names of params, with their types if typed.
+func list(params []param, typed bool) string {
+	var s []string
+	for _, p := range params {
+		if typed {
+			s = append(s, p.name+" "+p.typ)
+		} else {
+			s = append(s, p.name)
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+// resultList returns the types of results, as in a signature.
+func resultList(results []param) string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.typ)
+	}
+	if len(s) > 1 {
+		return "(" + strings.Join(s, ", ") + ")"
+	}
+	return strings.Join(s, "")
+}
+
+// exported returns name with its first letter in upper case.
+func exported(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// normalizeComponents lowercases the nicks of a project's components and
+// drops the paths that are not valid globs or have no positive weight.
+func normalizeComponents(project string, components map[string]*Component) map[string]*Component {
+	normalized := make(map[string]*Component, len(components))
+	for name, comp := range components {
+		c := &Component{Title: comp.Title, Paths: map[string]int{}}
+		for _, nick := range comp.People {
+			c.People = append(c.People, strings.ToLower(nick))
+		}
+		c.People = removeDuplicates(c.People)
+		for glob, weight := range comp.Paths {
+			if _, err := path.Match(glob, ""); err != nil || weight <= 0 {
+				logrus.Warnf("%s: invalid path %q (weight %d) of component %s", project, glob, weight, name)
+				continue
+			}
+			c.Paths[glob] = weight
+		}
+		normalized[name] = c
+	}
+	return normalized
+}
+
+// reviewer is a maintainer responsible for a changed file.
+type reviewer struct {
+	Nick   string `json:"nick"`
+	GitHub string `json:"github,omitempty"`
+
+	// Weight is the sum, over the components matching the file, of the
+	// weight of their heaviest matching path. Maintainers of the project
+	// owning no matching component weigh 0.
+	Weight     int      `json:"weight"`
+	Components []string `json:"components,omitempty"`
+}
+
+// reviewers returns the maintainers responsible for the file at name in the
+// project whose Org section is section: the owners of its matching
+// components, heaviest first, then the other maintainers of the project.
+func reviewers(m Maintainers, section, name string) []reviewer {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	byNick := map[string]*reviewer{}
+	add := func(nick string) *reviewer {
+		r, ok := byNick[nick]
+		if !ok {
+			r = &reviewer{Nick: nick, GitHub: m.People[nick].GitHub}
+			byNick[nick] = r
+		}
+		return r
+	}
+
+	var names []string
+	for n := range m.Components[section] {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		comp := m.Components[section][n]
+		weight := 0
+		for glob, w := range comp.Paths {
+			if w > weight && globMatch(glob, name) {
+				weight = w
+			}
+		}
+		if weight == 0 {
+			continue
+		}
+		for _, nick := range comp.People {
+			r := add(nick)
+			r.Weight += weight
+			r.Components = append(r.Components, n)
+		}
+	}
+	if o, ok := m.Org[section]; ok {
+		for _, nick := range o.People {
+			add(nick)
+		}
+	}
+
+	list := make([]reviewer, 0, len(byNick))
+	for _, r := range byNick {
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Weight != list[j].Weight {
+			return list[i].Weight > list[j].Weight
+		}
+		return list[i].Nick < list[j].Nick
+	})
+	return list
+}
+
+// globMatch reports whether name matches the glob pattern, where "**"
+// matches any number of path elements and other elements are matched with
+// path.Match.
+func globMatch(pattern, name string) bool {
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElements(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// serveReviewers serves the ordered reviewers of the file given by the path
+// query parameter in the project given by the project parameter.
+func (s *server) serveReviewers(w http.ResponseWriter, r *http.Request) {
+	project, file := r.URL.Query().Get("project"), r.URL.Query().Get("path")
+	if project == "" || file == "" {
+		http.Error(w, "the project and path parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	last := s.last
+	s.mu.RUnlock()
+	if last == nil {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "MAINTAINERS file not generated yet", http.StatusServiceUnavailable)
+		return
+	}
+	section := project
+	if alias, ok := s.config.Aliases[project]; ok {
+		section = alias
+	}
+	if _, ok := last.Org[section]; !ok {
+		http.Error(w, "unknown project "+project, http.StatusNotFound)
+		return
+	}
+
+	b, err := json.MarshalIndent(struct {
+		Project   string     `json:"project"`
+		Path      string     `json:"path"`
+		Reviewers []reviewer `json:"reviewers"`
+	}{section, file, reviewers(*last, section, file)}, "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(append(b, '\n'))
+}
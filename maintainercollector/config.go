@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Config is the optional configuration file of the collector, passed with
+// the -config flag.
+type Config struct {
+	// Voting holds the voting rules for changes to the maintainers of a
+	// project, keyed by project name. The "default" rule applies to projects
+	// without a rule of their own.
+	Voting map[string]VotingRule
+
+	// Committees and WorkingGroups are added to the combined file, along
+	// with those defined in the Governance file.
+	Committees    map[string]*Group
+	WorkingGroups map[string]*Group
+
+	// Governance is a TOML file in a governance repository, given as
+	// "org/project/path", defining more Committees and WorkingGroups.
+	Governance string
+
+	// Issues is a repository, as "org/project", in which each run opens or
+	// updates a tracking issue listing the governance problems it found.
+	Issues string
+
+	// Projects replaces the default list of projects to collect, as
+	// "org/project" or, for projects of the docker org, "project".
+	Projects []string
+
+	// MergeDuplicates merges the maintainers of projects with the same name
+	// in different orgs, such as mirrors, instead of failing.
+	MergeDuplicates bool
+
+	// Aliases maps project names to the name of the Org section they are
+	// listed under, grouping renamed projects under a single section, e.g.
+	// "v1.10-migrator" = "migrator". Maintainers of grouped projects are
+	// merged.
+	Aliases map[string]string
+
+	// DisplayNames maps Org section names to human-friendly names, written
+	// as the title of the section.
+	DisplayNames map[string]string
+
+	// APITokens maps the bearer tokens accepted by the serve command to the
+	// view ("public" or "internal") their holders see. When set, requests
+	// without a token get the public view, without email addresses.
+	APITokens map[string]string
+
+	// Webhooks are notified of the changes to the maintainers after each
+	// regeneration of the serve command, or in digests.
+	Webhooks []Webhook
+
+	// GitHubWebhookSecrets are the secrets of the GitHub webhooks received
+	// by the serve command under /github-webhook, which regenerates the
+	// combined file when a MAINTAINERS file is pushed. Deliveries signed
+	// with any of them are accepted, so that a new secret can be added
+	// before the old one is removed. Without secrets, the endpoint is
+	// disabled.
+	GitHubWebhookSecrets []string
+
+	// ConsoleUsers are the GitHub logins allowed to use the console served
+	// by serve -console. Without them, the console is open to the people
+	// of the combined maintainers.
+	ConsoleUsers []string
+
+	// Encodings sets the line endings and byte order mark of artifacts,
+	// keyed by file name: MAINTAINERS, MAINTAINERS.json or
+	// MAINTAINERS.by-email.json. Artifacts are written with LF line
+	// endings and no byte order mark by default.
+	Encodings map[string]Encoding
+
+	// Sources are external programs providing the maintainers of projects.
+	Sources []Source
+
+	// Exporters are run with the combined maintainers after each run of
+	// generate.
+	Exporters []Exporter
+
+	// IdentityProviders are the systems -enrich-profiles completes the
+	// People entries from, in order. It defaults to GitHub alone.
+	IdentityProviders []IdentityProvider
+
+	// Validation holds extra rules checked against the maintainers of a
+	// project, keyed by project name. The "default" rules apply to projects
+	// without rules of their own. Violations are governance findings.
+	Validation map[string]ValidationRules
+
+	// MaxProjectsPerPerson is the number of projects a person can maintain
+	// before being reported as over-extended by the audit and the stats
+	// command. 0 disables the check.
+	MaxProjectsPerPerson int
+
+	// TermNoticeDays is how many days before its end a term-limited
+	// membership is reported as expiring. It defaults to 30.
+	TermNoticeDays int
+
+	// Postgres is the database the combined maintainers are loaded into
+	// after each run of generate.
+	Postgres *PostgresSink
+
+	// Gates are the data quality thresholds checked by generate.
+	Gates Gates
+
+	// Teams lists, per project, the GitHub teams ("org/team") new
+	// maintainers must be invited to. See the onboard command.
+	Teams map[string][]string
+}
+
+// defaultVotingRule follows the governance rules: the BDFL and at least 66%
+// of the current maintainers must approve.
+var defaultVotingRule = VotingRule{Threshold: "0.66", Roles: []string{"bdfl"}}
+
+var config = Config{
+	Voting: map[string]VotingRule{"default": defaultVotingRule},
+}
+
+// loadConfig reads the configuration file at path, if any, into config and
+// projects.
+func loadConfig(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	c, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+	config = c
+	if len(c.Projects) > 0 {
+		projects = c.Projects
+	}
+	return nil
+}
+
+// readConfig reads and validates the configuration file at path.
+func readConfig(path string) (Config, error) {
+	var c Config
+	if err := decodeFile(path, &c); err != nil {
+		return c, err
+	}
+
+	if c.Voting == nil {
+		c.Voting = map[string]VotingRule{}
+	}
+	if _, ok := c.Voting["default"]; !ok {
+		c.Voting["default"] = defaultVotingRule
+	}
+	for project, rule := range c.Voting {
+		if _, _, err := rule.required(1); err != nil {
+			return c, fmt.Errorf("%s: Voting.%s: %v", path, project, err)
+		}
+	}
+	if !c.MergeDuplicates {
+		if err := duplicateProjects(c.Projects, false); err != nil {
+			return c, fmt.Errorf("%s: Projects: %v", path, err)
+		}
+	}
+	for from, to := range c.Aliases {
+		if _, ok := c.Aliases[to]; ok {
+			return c, fmt.Errorf("%s: Aliases: %s is aliased to %s, itself an alias", path, from, to)
+		}
+	}
+	for _, view := range c.APITokens {
+		if _, ok := redactionPolicies[view]; !ok {
+			return c, fmt.Errorf("%s: APITokens: unknown view %q", path, view)
+		}
+	}
+	for i, e := range c.Exporters {
+		if e.Name == "" || len(e.Command) == 0 {
+			return c, fmt.Errorf("%s: Exporters[%d]: Name and Command are required", path, i)
+		}
+		if e.Timeout != "" {
+			if _, err := time.ParseDuration(e.Timeout); err != nil {
+				return c, fmt.Errorf("%s: Exporters.%s.Timeout: %v", path, e.Name, err)
+			}
+		}
+	}
+	for name, e := range c.Encodings {
+		known := false
+		for _, n := range artifactNames {
+			known = known || n == name
+		}
+		if !known {
+			return c, fmt.Errorf("%s: Encodings: unknown artifact %q, expected one of %s", path, name, strings.Join(artifactNames, ", "))
+		}
+		if err := e.check(); err != nil {
+			return c, fmt.Errorf("%s: Encodings.%s: %v", path, name, err)
+		}
+	}
+	for i, secret := range c.GitHubWebhookSecrets {
+		if secret == "" {
+			return c, fmt.Errorf("%s: GitHubWebhookSecrets[%d] is empty", path, i)
+		}
+	}
+	for i, s := range c.Sources {
+		if s.Project == "" || len(s.Command) == 0 {
+			return c, fmt.Errorf("%s: Sources[%d]: Project and Command are required", path, i)
+		}
+		if s.Timeout != "" {
+			if _, err := time.ParseDuration(s.Timeout); err != nil {
+				return c, fmt.Errorf("%s: Sources[%d].Timeout: %v", path, i, err)
+			}
+		}
+	}
+	for i, p := range c.IdentityProviders {
+		if err := p.check(); err != nil {
+			return c, fmt.Errorf("%s: IdentityProviders[%d]: %v", path, i, err)
+		}
+		if p.Timeout != "" {
+			if _, err := time.ParseDuration(p.Timeout); err != nil {
+				return c, fmt.Errorf("%s: IdentityProviders[%d].Timeout: %v", path, i, err)
+			}
+		}
+	}
+	if c.MaxProjectsPerPerson < 0 {
+		return c, fmt.Errorf("%s: MaxProjectsPerPerson cannot be negative", path)
+	}
+	for project, rules := range c.Validation {
+		if err := rules.check(); err != nil {
+			return c, fmt.Errorf("%s: Validation.%s: %v", path, project, err)
+		}
+	}
+	if p := c.Postgres; p != nil {
+		if p.URL == "" {
+			return c, fmt.Errorf("%s: Postgres: no URL", path)
+		}
+		if p.Timeout != "" {
+			if _, err := time.ParseDuration(p.Timeout); err != nil {
+				return c, fmt.Errorf("%s: Postgres.Timeout: %v", path, err)
+			}
+		}
+	}
+	for i, h := range c.Webhooks {
+		if h.URL == "" {
+			return c, fmt.Errorf("%s: Webhooks[%d]: no URL", path, i)
+		}
+		if _, err := h.digestPeriod(); err != nil {
+			return c, fmt.Errorf("%s: Webhooks[%d].Digest: expected hourly, daily or a duration: %v", path, i, err)
+		}
+		if _, err := h.dedupWindow(); err != nil {
+			return c, fmt.Errorf("%s: Webhooks[%d].DedupWindow: %v", path, i, err)
+		}
+		if h.Secret == "" {
+			logrus.Warnf("%s: Webhooks[%d] has no Secret, its consumer cannot check that the notifications come from the collector", path, i)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// consoleTemplate is the page of the console, see console.html.
+var consoleTemplate = template.Must(template.New("console").Parse(consoleui))
+
+// console is a web UI to browse the combined maintainers and their audit,
+// and to draft changes to the maintainers of projects, which are opened as
+// pull requests. It is enabled with serve -console, and users log in
+// through the portal.
+//
+//	GET  <prefix>/portal/console/             the console
+//	GET  <prefix>/portal/console/roster.json  the internal view of the maintainers
+//	POST <prefix>/portal/console/propose      drafts a proposal from the project,
+//	                                          action (add or remove), handle and
+//	                                          reason form values
+type console struct {
+	s      *server
+	portal *portal
+	prefix string
+}
+
+// newConsole returns the console of a tenant, logging in with p.
+func newConsole(s *server, p *portal) *console {
+	c := &console{s: s, portal: p, prefix: p.prefix + "console/"}
+	p.home = c.prefix
+	return c
+}
+
+// register adds the endpoints of the console to mux.
+func (c *console) register(mux *http.ServeMux) {
+	mux.HandleFunc(c.prefix, c.serveUI)
+	mux.HandleFunc(c.prefix+"roster.json", c.serveRoster)
+	mux.HandleFunc(c.prefix+"propose", c.servePropose)
+}
+
+// user returns the GitHub login of the session of r, if it is allowed to
+// use the console: it is one of the ConsoleUsers of the configuration or,
+// if there are none, one of the people of the combined maintainers.
+func (c *console) user(r *http.Request) (string, bool) {
+	login, ok := c.portal.sessionLogin(r)
+	if !ok {
+		return "", false
+	}
+	if len(c.s.config.ConsoleUsers) > 0 {
+		return login, containsFold(c.s.config.ConsoleUsers, login)
+	}
+
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	if c.s.last == nil {
+		return login, false
+	}
+	for _, p := range c.s.last.People {
+		if strings.EqualFold(p.GitHub, login) {
+			return login, true
+		}
+	}
+	return login, false
+}
+
+// deny responds to a request of login, empty if not logged in, that is not
+// allowed to use the console.
+func (c *console) deny(w http.ResponseWriter, login string) {
+	if login == "" {
+		http.Error(w, "not logged in, see "+c.portal.prefix+"login", http.StatusUnauthorized)
+		return
+	}
+	http.Error(w, login+" is not allowed to use the console", http.StatusForbidden)
+}
+
+func (c *console) serveUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != c.prefix {
+		http.NotFound(w, r)
+		return
+	}
+	login, ok := c.user(r)
+	if !ok && login == "" {
+		http.Redirect(w, r, c.portal.prefix+"login", http.StatusFound)
+		return
+	}
+	if !ok {
+		c.deny(w, login)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := consoleTemplate.Execute(w, struct {
+		Login    string
+		Projects []string
+	}{login, c.s.projects}); err != nil {
+		logrus.Errorf("%sconsole: %v", c.s.logPrefix(), err)
+	}
+}
+
+func (c *console) serveRoster(w http.ResponseWriter, r *http.Request) {
+	if login, ok := c.user(r); !ok {
+		c.deny(w, login)
+		return
+	}
+
+	c.s.mu.RLock()
+	v := c.s.views[viewInternal]
+	c.s.mu.RUnlock()
+	if v == nil {
+		http.Error(w, "MAINTAINERS file not generated yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(v.json)
+}
+
+func (c *console) servePropose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Forms of other sites cannot set the header, which protects the
+	// session cookie against cross-site request forgery.
+	if r.Header.Get("X-Console-Request") == "" {
+		http.Error(w, "missing X-Console-Request header", http.StatusForbidden)
+		return
+	}
+	login, ok := c.user(r)
+	if !ok {
+		c.deny(w, login)
+		return
+	}
+
+	project, action := r.FormValue("project"), r.FormValue("action")
+	handle, reason := strings.TrimPrefix(strings.TrimSpace(r.FormValue("handle")), "@"), strings.TrimSpace(r.FormValue("reason"))
+	if !containsFold(c.s.projects, project) {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return
+	}
+	if action != "add" && action != "remove" {
+		http.Error(w, "action must be add or remove", http.StatusBadRequest)
+		return
+	}
+	if handle == "" || reason == "" {
+		http.Error(w, "handle and reason are required", http.StatusBadRequest)
+		return
+	}
+
+	org, name := getProjectOrg(project)
+	pr, err := draftProposal(org, name, action, handle, reason, login)
+	if err != nil {
+		logrus.Warnf("%sconsole: proposal of %s failed: %v", c.s.logPrefix(), login, err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	logrus.Infof("%sconsole: %s/%s: %s opened %s", c.s.logPrefix(), org, name, login, pr)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"pull": pr})
+}
+
+// draftProposal opens a pull request adding handle to, or removing it from,
+// the maintainers of org/project, on behalf of login. It returns the URL of
+// the pull request.
+func draftProposal(org, project, action, handle, reason, login string) (string, error) {
+	file, err := getRepoFile(org, project, "MAINTAINERS")
+	if err != nil {
+		return "", err
+	}
+
+	var current MaintainersDepreciated
+	if _, err := toml.Decode(string(file.Content), &current); err != nil {
+		return "", fmt.Errorf("%s/%s: parsing MAINTAINERS file failed: %v", org, project, err)
+	}
+	section, people := maintainersSection(current)
+	if section == "" {
+		return "", fmt.Errorf("%s/%s: MAINTAINERS file has no maintainers section", org, project)
+	}
+
+	var updated, title, branch string
+	switch action {
+	case "add":
+		if containsFold(people, handle) {
+			return "", fmt.Errorf("%s/%s: %s is already a maintainer", org, project, handle)
+		}
+		person, err := lookupNominee(handle, "", "")
+		if err != nil {
+			return "", fmt.Errorf("%s/%s: %v", org, project, err)
+		}
+		if updated, err = addMaintainer(string(file.Content), section, strings.ToLower(handle), person); err != nil {
+			return "", fmt.Errorf("%s/%s: %v", org, project, err)
+		}
+		title, branch = fmt.Sprintf("Add %s as a maintainer", handle), uniqueBranch("nominate-"+strings.ToLower(handle))
+	case "remove":
+		nick := ""
+		for _, n := range people {
+			if strings.EqualFold(n, handle) || strings.EqualFold(current.People[n].GitHub, handle) {
+				nick = n
+			}
+		}
+		if nick == "" {
+			return "", fmt.Errorf("%s/%s: %s is not a maintainer", org, project, handle)
+		}
+		if updated, err = editSectionPeople(string(file.Content), section, func(nicks []string) []string {
+			var kept []string
+			for _, n := range nicks {
+				if n != nick {
+					kept = append(kept, n)
+				}
+			}
+			return kept
+		}); err != nil {
+			return "", fmt.Errorf("%s/%s: %v", org, project, err)
+		}
+		title, branch = fmt.Sprintf("Remove %s as a maintainer", nick), uniqueBranch("remove-"+strings.ToLower(nick))
+	}
+
+	body := fmt.Sprintf("@%s drafted this proposal in the maintainers console:\n\n> %s\n\n"+
+		"Per the project governance, this change requires %s.\n\nMaintainers, please vote by approving or requesting changes on this pull request.\n",
+		login, strings.Replace(reason, "\n", "\n> ", -1), requiredApprovals(project, people))
+	return openPullRequest(org, project, file, branch, title, body, updated)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+)
+
+// Size of the charts of the dashboard, in pixels.
+const (
+	chartWidth  = 360
+	chartHeight = 100
+)
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Maintainers dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.project { display: inline-block; vertical-align: top; margin: 0 2em 2em 0; }
+h2 { font-size: 1.1em; margin-bottom: 0.2em; }
+.caption { color: #666; font-size: 0.8em; }
+svg { display: block; border: 1px solid #ddd; margin-bottom: 0.5em; }
+</style>
+</head>
+<body>
+<h1>Maintainers dashboard</h1>
+<p>{{len .History}} snapshots from {{.From}} to {{.To}}.</p>
+{{range .Projects}}
+<div class="project">
+<h2>{{.Name}} ({{.Current}} maintainers)</h2>
+<div class="caption">Maintainers over time</div>
+{{.Count}}
+<div class="caption">Added (green) and removed (red) per quarter</div>
+{{.Churn}}
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// dashboardProject is a project of the dashboard, with its charts as SVG.
+type dashboardProject struct {
+	Name    string
+	Current int
+	Count   template.HTML
+	Churn   template.HTML
+}
+
+// dashboardCmd implements the dashboard command.
+func dashboardCmd(args []string) error {
+	fs := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	output := fs.String("o", "dashboard.html", "write the dashboard to this file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dashboard [options]\n\nRenders the history store given with -history as an HTML dashboard.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if historyFile == "" {
+		return fmt.Errorf("dashboard: requires -history")
+	}
+	history, err := loadHistory(historyFile)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("dashboard: %s has no snapshots", historyFile)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeDashboard(f, history)
+}
+
+// writeDashboard writes the HTML dashboard of the history, with the trend
+// charts of each project.
+func writeDashboard(w io.Writer, history []snapshot) error {
+	data := struct {
+		History  []snapshot
+		From, To string
+		Projects []dashboardProject
+	}{
+		History: history,
+		From:    history[0].Time.Format("2006-01-02"),
+		To:      history[len(history)-1].Time.Format("2006-01-02"),
+	}
+
+	for _, p := range historyProjects(history) {
+		data.Projects = append(data.Projects, dashboardProject{
+			Name:    p,
+			Current: len(history[len(history)-1].Projects[p]),
+			Count:   countChart(history, p),
+			Churn:   churnChart(churnByQuarter(history, p)),
+		})
+	}
+	return dashboardTemplate.Execute(w, data)
+}
+
+// countChart draws the number of maintainers of project over time as a step
+// line.
+func countChart(history []snapshot, project string) template.HTML {
+	from, to := history[0].Time, time.Now()
+	if !history[len(history)-1].Time.Before(to) {
+		to = history[len(history)-1].Time.Add(time.Second)
+	}
+	max := 1
+	for _, s := range history {
+		if n := len(s.Projects[project]); n > max {
+			max = n
+		}
+	}
+	x := func(t time.Time) float64 {
+		return float64(chartWidth) * float64(t.Sub(from)) / float64(to.Sub(from))
+	}
+	y := func(n int) float64 {
+		return chartHeight - 5 - float64(chartHeight-10)*float64(n)/float64(max)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<svg width="%d" height="%d"><polyline fill="none" stroke="#1f77b4" stroke-width="2" points="`, chartWidth, chartHeight)
+	prev := -1
+	for _, s := range history {
+		n := len(s.Projects[project])
+		if prev >= 0 {
+			fmt.Fprintf(buf, "%.1f,%.1f ", x(s.Time), y(prev))
+		}
+		fmt.Fprintf(buf, "%.1f,%.1f ", x(s.Time), y(n))
+		prev = n
+	}
+	fmt.Fprintf(buf, `%.1f,%.1f"/><text x="2" y="12" font-size="10">%d</text></svg>`, float64(chartWidth), y(prev), max)
+	return template.HTML(buf.String())
+}
+
+// churnChart draws the maintainers added and removed per quarter as bars
+// above and below a middle axis.
+func churnChart(churn []quarterChurn) template.HTML {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<svg width="%d" height="%d"><line x1="0" y1="%d" x2="%d" y2="%d" stroke="#999"/>`,
+		chartWidth, chartHeight, chartHeight/2, chartWidth, chartHeight/2)
+	if len(churn) == 0 {
+		fmt.Fprintf(buf, `<text x="2" y="12" font-size="10">no changes</text></svg>`)
+		return template.HTML(buf.String())
+	}
+
+	max := 1
+	for _, c := range churn {
+		if c.Added > max {
+			max = c.Added
+		}
+		if c.Removed > max {
+			max = c.Removed
+		}
+	}
+	width := float64(chartWidth) / float64(len(churn))
+	scale := float64(chartHeight/2-12) / float64(max)
+	for i, c := range churn {
+		x := float64(i)*width + width*0.1
+		fmt.Fprintf(buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#2ca02c"><title>%s: %d added</title></rect>`,
+			x, float64(chartHeight/2)-float64(c.Added)*scale, width*0.8, float64(c.Added)*scale, c.Quarter, c.Added)
+		fmt.Fprintf(buf, `<rect x="%.1f" y="%d" width="%.1f" height="%.1f" fill="#d62728"><title>%s: %d removed</title></rect>`,
+			x, chartHeight/2, width*0.8, float64(c.Removed)*scale, c.Quarter, c.Removed)
+		fmt.Fprintf(buf, `<text x="%.1f" y="%d" font-size="9">%s</text>`, x, chartHeight-1, c.Quarter)
+	}
+	fmt.Fprintf(buf, `</svg>`)
+	return template.HTML(buf.String())
+}
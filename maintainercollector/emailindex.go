@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// writeEmailIndex makes generate also write MAINTAINERS.by-email.json, set
+// with -email-index.
+var writeEmailIndex bool
+
+// emailIndex returns the reverse index of the combined maintainers used by
+// mail-based tooling: lowercased email address, then nick, then the sorted
+// Org sections listing that nick. People without an email address are left
+// out; people listed in no section map to an empty list.
+func emailIndex(m Maintainers) map[string]map[string][]string {
+	sections := map[string][]string{}
+	for name, o := range m.Org {
+		for _, nick := range o.People {
+			sections[nick] = append(sections[nick], name)
+		}
+	}
+
+	index := map[string]map[string][]string{}
+	for nick, p := range m.People {
+		email := strings.ToLower(strings.TrimSpace(p.Email))
+		if email == "" {
+			continue
+		}
+		if index[email] == nil {
+			index[email] = map[string][]string{}
+		}
+		names := append([]string{}, sections[nick]...)
+		sort.Strings(names)
+		index[email][nick] = names
+	}
+	return index
+}
+
+// encodeEmailIndex returns the email index of m as JSON.
+func encodeEmailIndex(m Maintainers) ([]byte, error) {
+	b, err := json.MarshalIndent(emailIndex(m), "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("JSON encoding error: %v", err)
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// utf8BOM is the UTF-8 encoded byte order mark.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// artifactNames are the artifacts of generate, and of serve with an output
+// directory, whose Encoding can be configured.
+var artifactNames = []string{"MAINTAINERS", "MAINTAINERS.json", "MAINTAINERS.by-email.json"}
+
+// Encoding is how a text file is written, for tools which expect Windows
+// conventions.
+type Encoding struct {
+	// LineEndings is "lf" (the default) or "crlf".
+	LineEndings string
+
+	// BOM starts the file with a UTF-8 byte order mark.
+	BOM bool
+}
+
+// check returns an error if the settings of e are invalid.
+func (e Encoding) check() error {
+	switch e.LineEndings {
+	case "", "lf", "crlf":
+		return nil
+	default:
+		return fmt.Errorf("unknown LineEndings %q, expected lf or crlf", e.LineEndings)
+	}
+}
+
+// apply encodes data, with LF line endings and no byte order mark, with e.
+func (e Encoding) apply(data []byte) []byte {
+	if e.LineEndings == "crlf" {
+		data = bytes.Replace(data, []byte("\n"), []byte("\r\n"), -1)
+	}
+	if e.BOM {
+		data = append(append([]byte{}, utf8BOM...), data...)
+	}
+	return data
+}
+
+// detectEncoding returns the encoding of data: CRLF line endings if its
+// first line ends with CRLF, and whether it starts with a byte order mark.
+func detectEncoding(data []byte) Encoding {
+	e := Encoding{LineEndings: "lf", BOM: bytes.HasPrefix(data, utf8BOM)}
+	if i := bytes.IndexByte(data, '\n'); i > 0 && data[i-1] == '\r' {
+		e.LineEndings = "crlf"
+	}
+	return e
+}
+
+// normalizeText returns data without a leading byte order mark and with LF
+// line endings, as expected by the parsers and editors of MAINTAINERS and
+// configuration files.
+func normalizeText(data []byte) []byte {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if bytes.IndexByte(data, '\r') < 0 {
+		return data
+	}
+	return bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/docker/opensource/maintainercollector/clients"
+)
+
+// encryptedMagic prefixes the files encrypted by writePrivate.
+const encryptedMagic = "maintainercollector-aes256gcm\n"
+
+// encryptionKey is the AES-256 key of the files holding personal data (the
+// cache, the state file, the verification store and the history store),
+// loaded with -key-file. Without a key, these files are stored in clear.
+var encryptionKey []byte
+
+// loadEncryptionKey reads a hex encoded 32 byte key from path.
+func loadEncryptionKey(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != 32 {
+		return fmt.Errorf("%s: expected a hex encoded 32 byte key", path)
+	}
+	encryptionKey = key
+	return nil
+}
+
+// store persists the files of the collector holding personal data. See
+// package clientstest for a mock.
+type store = clients.Store
+
+// files is the store used by all commands.
+var files store = privateFiles{}
+
+// privateFiles is the store on disk, encrypting the files with
+// encryptionKey if set.
+type privateFiles struct{}
+
+func (privateFiles) Load(path string) ([]byte, error) {
+	return readPrivate(path)
+}
+
+func (privateFiles) Save(path string, data []byte, perm os.FileMode) error {
+	return writePrivate(path, data, perm)
+}
+
+// writePrivate writes a file holding personal data, encrypted with
+// encryptionKey if set. The permissions of an existing file are set to perm
+// too, so that files written before are no longer readable by others.
+func writePrivate(path string, data []byte, perm os.FileMode) error {
+	if encryptionKey == nil {
+		return writeFileMode(path, data, perm)
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	out := append([]byte(encryptedMagic), nonce...)
+	out = gcm.Seal(out, nonce, data, []byte(encryptedMagic))
+	return writeFileMode(path, out, perm)
+}
+
+// writeFileMode writes data to the file at path, with the permissions perm
+// whether it exists or not.
+func writeFileMode(path string, data []byte, perm os.FileMode) error {
+	if err := ioutil.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	return os.Chmod(path, perm)
+}
+
+// readPrivate reads a file written by writePrivate. Files stored in clear
+// are returned as is, so that enabling encryption does not require
+// migrating existing files.
+func readPrivate(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(b, []byte(encryptedMagic)) {
+		return b, err
+	}
+	if encryptionKey == nil {
+		return nil, fmt.Errorf("%s is encrypted, use -key-file", path)
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	b = b[len(encryptedMagic):]
+	if len(b) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s: truncated encrypted file", path)
+	}
+	data, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], []byte(encryptedMagic))
+	if err != nil {
+		return nil, fmt.Errorf("%s: decryption failed: %v", path, err)
+	}
+	return data, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
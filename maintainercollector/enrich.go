@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Settings of the profile enrichment, set with -enrich-profiles,
+// -enrich-delay and -enrich-budget.
+var (
+	enrichProfiles bool
+	enrichDelay    = time.Second
+	enrichBudget   int
+)
+
+// profileMaxAge is how long a checkpointed GitHub profile is used before
+// being fetched again.
+const profileMaxAge = 30 * 24 * time.Hour
+
+// checkpointEvery is the number of profiles fetched between two saves of the
+// state file, so that an interrupted run resumes close to where it stopped.
+const checkpointEvery = 20
+
+// profileState is the checkpoint of the GitHub profile of a person.
+type profileState struct {
+	GitHub  string    `json:"github"`
+	Name    string    `json:"name,omitempty"`
+	Email   string    `json:"email,omitempty"`
+	Company string    `json:"company,omitempty"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// enrichPeople completes the name, email and company of the people of m
+// from the identity providers of the configuration, GitHub by default. The
+// providers are asked in order, until the entry of a person is complete.
+func enrichPeople(m *Maintainers) {
+	providers, err := newIdentityProviders(config.IdentityProviders)
+	if err != nil {
+		logrus.Errorf("profile enrichment: %v", err)
+		return
+	}
+
+	var nicks []string
+	for nick := range m.People {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+
+	for _, nick := range nicks {
+		p := m.People[nick]
+		for _, ip := range providers {
+			if p.Name != "" && p.Email != "" && p.Company != "" {
+				break
+			}
+			id, ok := ip.lookup(nick, p)
+			if !ok {
+				continue
+			}
+			if p.Name == "" {
+				p.Name = id.Name
+			}
+			if p.Email == "" {
+				p.Email = id.Email
+			}
+			if p.Company == "" {
+				p.Company = id.Company
+			}
+		}
+		m.People[nick] = p
+	}
+
+	for _, ip := range providers {
+		ip.finish()
+	}
+}
+
+// githubProvider looks people up by their GitHub profile. Profiles are
+// fetched one at a time, enrichDelay apart, and checkpointed in the state:
+// people whose profile was fetched recently, by this run or an earlier one,
+// are not fetched again. Fetching stops early, to be resumed by the next
+// run, when enrichBudget profiles were fetched or GitHub rate limits the
+// requests.
+type githubProvider struct {
+	fetched, pending int
+}
+
+func (g *githubProvider) lookup(nick string, p Person) (identity, bool) {
+	if p.GitHub == "" {
+		return identity{}, false
+	}
+
+	cp, ok := runState.profile(nick)
+	if !ok || cp.GitHub != p.GitHub || time.Since(cp.Fetched) > profileMaxAge {
+		if (enrichBudget > 0 && g.fetched >= enrichBudget) || g.pending > 0 {
+			g.pending++
+			return identity{}, false
+		}
+		if g.fetched > 0 {
+			time.Sleep(enrichDelay)
+		}
+
+		var user struct {
+			Name    string `json:"name"`
+			Email   string `json:"email"`
+			Company string `json:"company"`
+		}
+		if err := githubGet("/users/"+p.GitHub, &user); err != nil {
+			if isRateLimited(err) {
+				logrus.Warnf("profile enrichment rate limited after %d profiles, resuming on the next run", g.fetched)
+				g.pending++
+				return identity{}, false
+			}
+			logrus.Warnf("%s: fetching the GitHub profile of %s failed: %v", nick, p.GitHub, err)
+			return identity{}, false
+		}
+		cp = &profileState{GitHub: p.GitHub, Name: user.Name, Email: user.Email, Company: user.Company, Fetched: time.Now().UTC()}
+		runState.checkpointProfile(nick, cp)
+		g.fetched++
+		if g.fetched%checkpointEvery == 0 {
+			saveCheckpoint()
+		}
+	}
+	return identity{Name: cp.Name, Email: cp.Email, Company: cp.Company}, true
+}
+
+func (g *githubProvider) finish() {
+	if g.pending > 0 {
+		logrus.Infof("profile enrichment: %d profiles fetched, %d left for the next run", g.fetched, g.pending)
+	}
+	saveCheckpoint()
+}
+
+// saveCheckpoint saves the state file, if any, logging failures.
+func saveCheckpoint() {
+	if statePath == "" {
+		return
+	}
+	if err := runState.save(statePath); err != nil {
+		logrus.Errorf("%s: saving the checkpoint failed: %v", statePath, err)
+	}
+}
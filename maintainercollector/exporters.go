@@ -0,0 +1,55 @@
+package main
+
+import "github.com/Sirupsen/logrus"
+
+// Exporter is an external program receiving the combined maintainers after
+// each run, so that organizations can feed their own systems without
+// forking the collector. The maintainers are written to its standard input
+// as the JSON of MAINTAINERS.json, with the governance findings. Its output
+// is logged.
+type Exporter struct {
+	Name string
+
+	// Command is the program and its arguments.
+	Command []string
+
+	// Timeout, as accepted by time.ParseDuration, defaults to one minute.
+	Timeout string
+}
+
+func init() {
+	if err := registerStage(stage{"export", exportStage}, "encode"); err != nil {
+		panic(err)
+	}
+}
+
+// exportStage runs the exporters of the configuration. Failures are logged
+// and do not fail the run.
+func exportStage(c *collection) error {
+	if len(config.Exporters) == 0 {
+		return nil
+	}
+
+	m := c.Maintainers
+	m.Findings = c.Findings
+	input, err := encodeMaintainersJSON(m)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range config.Exporters {
+		if err := runExporter(e, input); err != nil {
+			logrus.Errorf("exporter %s: %v", e.Name, err)
+			continue
+		}
+		logrus.Infof("exporter %s: done", e.Name)
+	}
+	return nil
+}
+
+// runExporter runs e with input on its standard input.
+func runExporter(e Exporter, input []byte) error {
+	out, err := runPlugin("exporter "+e.Name, e.Command, e.Timeout, []string{"MAINTAINERCOLLECTOR_EXPORTER=" + e.Name}, input)
+	logOutput("exporter "+e.Name, out)
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// fallbackPeople makes the enrich stage synthesize the People entry of
+// maintainers listed without one, set with -fallback-people.
+var fallbackPeople bool
+
+// addFallbackPeople synthesizes a minimal People entry, flagged as
+// AutoGenerated, for each person listed in an Org section of c without one
+// in any MAINTAINERS file. The nick is taken as GitHub handle: the entry
+// gets the name of the GitHub profile and its public email or, without one,
+// the author email of the person's recent commits to a project they
+// maintain. Nicks unknown to GitHub are left dangling.
+func addFallbackPeople(c *collection) {
+	m := c.Maintainers
+	missing := map[string]string{}
+	for name, o := range m.Org {
+		for _, nick := range o.People {
+			if _, ok := m.People[nick]; !ok && missing[nick] == "" {
+				missing[nick] = name
+			}
+		}
+	}
+	var nicks []string
+	for nick := range missing {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+
+	for _, nick := range nicks {
+		var user struct {
+			Login string `json:"login"`
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+		if err := githubGet("/users/"+nick, &user); err != nil {
+			logrus.Warnf("%s: no People entry and no GitHub profile to synthesize one: %v", nick, err)
+			continue
+		}
+
+		p := Person{Name: user.Name, Email: user.Email, GitHub: user.Login, AutoGenerated: true}
+		if p.Name == "" {
+			p.Name = nick
+		}
+		if p.Email == "" {
+			p.Email = commitEmail(c, nick, user.Login)
+		}
+		m.People[nick] = p
+		logrus.Infof("%s: synthesized a People entry from GitHub (listed in %s)", nick, missing[nick])
+	}
+}
+
+// commitEmail returns an author email of the recent commits of handle to a
+// project nick maintains, skipping GitHub noreply addresses, or "".
+func commitEmail(c *collection, nick, handle string) string {
+	for _, s := range c.Sources {
+		if !containsFold(s.Maintainers, nick) && !containsFold(s.Docs, nick) && !containsFold(s.Curators, nick) {
+			continue
+		}
+		emails, err := getCommitEmails(s.Org, s.Project, handle, 10)
+		if err != nil {
+			logrus.Debugf("%s/%s: fetching commits of %s failed: %v", s.Org, s.Project, handle, err)
+			continue
+		}
+		for _, e := range emails {
+			if !strings.HasSuffix(e, "@users.noreply.github.com") {
+				return e
+			}
+		}
+	}
+	return ""
+}
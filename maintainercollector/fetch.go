@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/opensource/maintainercollector/clients"
+)
+
+// fetcher downloads files from repositories. See package clientstest for a
+// mock.
+type fetcher = clients.Fetcher
+
+// rawFiles is the fetcher used to load the MAINTAINERS files. It is set by
+// the -source flag.
+var rawFiles fetcher = rawFetcher{}
+
+// namedFetcher is a fetcher along with the name of its source.
+type namedFetcher struct {
+	name string
+	fetcher
+}
+
+// fallbackFetcher tries each of its fetchers in turn, until one succeeds.
+type fallbackFetcher []namedFetcher
+
+func (f fallbackFetcher) Fetch(org string, project string, path string) ([]byte, error) {
+	b, _, err := f.fetchWithSource(org, project, path)
+	return b, err
+}
+
+func (f fallbackFetcher) fetchWithSource(org string, project string, path string) ([]byte, string, error) {
+	var errs []string
+	class := ""
+	for _, n := range f {
+		b, err := fetchWithRetries(n, org, project, path)
+		if err == nil {
+			if len(errs) > 0 {
+				logrus.Warnf("%s/%s: fetched %s from %s after: %s", org, project, path, n.name, strings.Join(errs, "; "))
+			}
+			return b, n.name, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", n.name, err))
+		// a file missing from any source is missing, whatever else failed
+		if c := errorClass(err); class == "" || c == classMissing {
+			class = c
+		}
+	}
+	// the errors of the fetchers already name the project
+	return nil, "", &fetchError{Class: class, Err: fmt.Errorf("fetching %s failed from all sources: %s", path, strings.Join(errs, "; "))}
+}
+
+// newFallbackFetcher returns the fetcher trying the raw and API sources,
+// starting with first ("raw" or "api").
+func newFallbackFetcher(first string) (fetcher, error) {
+	raw, api := namedFetcher{"raw", rawFetcher{}}, namedFetcher{"api", apiFetcher{}}
+	switch first {
+	case "raw":
+		return fallbackFetcher{raw, api}, nil
+	case "api":
+		return fallbackFetcher{api, raw}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q, expected raw or api", first)
+	}
+}
+
+// rawFetcher is the fetcher downloading from the master branch at ghRawUri.
+type rawFetcher struct{}
+
+func (rawFetcher) Fetch(org string, project string, path string) ([]byte, error) {
+	fileUrl := fmt.Sprintf("%s/%s/%s/master/%s", ghRawUri, org, project, path)
+
+	logrus.Infof("%s/%s: loading %s file from %v", org, project, path, fileUrl)
+
+	resp, err := http.Get(fileUrl)
+	if err != nil {
+		return nil, &fetchError{Class: classTransient, Err: fmt.Errorf("%s/%s: %v", org, project, err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &fetchError{Class: classifyStatus(resp), Err: fmt.Errorf("%s/%s: fetching %s failed: %s", org, project, path, resp.Status)}
+	}
+
+	file, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &fetchError{Class: classTransient, Err: fmt.Errorf("%s/%s: %v", org, project, err)}
+	}
+
+	return file, nil
+}
+
+// apiFetcher is the fetcher downloading from the master branch through the
+// contents endpoint of the GitHub API.
+type apiFetcher struct{}
+
+func (apiFetcher) Fetch(org string, project string, path string) ([]byte, error) {
+	logrus.Infof("%s/%s: loading %s file from the GitHub API", org, project, path)
+
+	var content struct {
+		Content string `json:"content"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/contents/%s?ref=master", org, project, path), &content); err != nil {
+		// errors other than error statuses are network errors
+		return nil, classified(fmt.Errorf("%s/%s: %w", org, project, err), classTransient)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.Replace(content.Content, "\n", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: decoding %s failed: %v", org, project, path, err)
+	}
+	return b, nil
+}
+
+// fetchWithSource downloads a file through rawFiles, and returns the name of
+// the source that served it, if known. The file is normalized, see
+// normalizeText.
+func fetchWithSource(org string, project string, path string) ([]byte, string, error) {
+	if f, ok := rawFiles.(fallbackFetcher); ok {
+		b, source, err := f.fetchWithSource(org, project, path)
+		return normalizeText(b), source, err
+	}
+	b, err := fetchWithRetries(rawFiles, org, project, path)
+	return normalizeText(b), "", err
+}
+
+// getRawFile downloads a file from a repository through rawFiles. The file
+// is normalized, see normalizeText.
+func getRawFile(org string, project string, path string) ([]byte, error) {
+	b, err := rawFiles.Fetch(org, project, path)
+	return normalizeText(b), err
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFallbackFetcher(t *testing.T) {
+	raw := serveFiles(nil)
+	api := serveFiles(map[string][]byte{
+		"docker/cli/MAINTAINERS":     []byte("\xEF\xBB\xBF[Org]\r\n"),
+		"docker/compose/MAINTAINERS": []byte("[Org]\n"),
+	})
+	useMocks(t, nil, fallbackFetcher{{"raw", raw}, {"api", api}}, nil, nil)
+
+	b, source, err := fetchWithSource("docker", "cli", "MAINTAINERS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != "api" || string(b) != "[Org]\n" {
+		t.Errorf("got %q from %q, want the normalized file from api", b, source)
+	}
+
+	_, _, err = fetchWithSource("docker", "missing", "MAINTAINERS")
+	if err == nil || errorClass(err) != classMissing {
+		t.Errorf("got error %v of class %q, want %q", err, errorClass(err), classMissing)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Classes of fetch failures, reported so that a project without a
+// MAINTAINERS file can be told apart from GitHub being down.
+const (
+	classMissing     = "missing"      // 404, the file does not exist
+	classRateLimited = "rate-limited" // 403 or 429 because of rate limits
+	classForbidden   = "forbidden"    // 401 or 403, e.g. a private repository
+	classTransient   = "transient"    // 5xx or network error, worth retrying
+	classParse       = "parse"        // the file is not valid TOML
+	classOther       = "other"
+)
+
+// fetchRetries is the number of times a transient fetch failure is retried,
+// waiting fetchBackoff, then twice as long, and so on.
+var (
+	fetchRetries = 2
+	fetchBackoff = time.Second
+)
+
+// fetchError is a classified fetch failure.
+type fetchError struct {
+	Class string
+	Err   error
+}
+
+func (e *fetchError) Error() string { return e.Err.Error() }
+func (e *fetchError) Unwrap() error { return e.Err }
+
+// classifyStatus returns the class of a failed HTTP response.
+func classifyStatus(resp *http.Response) string {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return classMissing
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return classRateLimited
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return classForbidden
+	case resp.StatusCode >= 500:
+		return classTransient
+	default:
+		return classOther
+	}
+}
+
+// errorClass returns the class of a fetch failure: the class of a
+// fetchError, or of a githubError, in the chain of err.
+func errorClass(err error) string {
+	var fe *fetchError
+	if errors.As(err, &fe) {
+		return fe.Class
+	}
+	var ge *githubError
+	if errors.As(err, &ge) {
+		switch {
+		case ge.StatusCode == http.StatusNotFound:
+			return classMissing
+		case ge.RateLimited:
+			return classRateLimited
+		case ge.StatusCode == http.StatusUnauthorized, ge.StatusCode == http.StatusForbidden:
+			return classForbidden
+		case ge.StatusCode >= 500:
+			return classTransient
+		}
+	}
+	return classOther
+}
+
+// classified returns err as a fetchError of its class, or of class if its
+// chain has no fetchError or githubError.
+func classified(err error, class string) error {
+	var fe *fetchError
+	var ge *githubError
+	if errors.As(err, &fe) || errors.As(err, &ge) {
+		class = errorClass(err)
+	}
+	return &fetchError{Class: class, Err: err}
+}
+
+// fetchWithRetries fetches a file through f, retrying transient failures.
+func fetchWithRetries(f fetcher, org, project, path string) ([]byte, error) {
+	backoff := fetchBackoff
+	for attempt := 0; ; attempt++ {
+		b, err := f.Fetch(org, project, path)
+		if err == nil || errorClass(err) != classTransient || attempt >= fetchRetries {
+			return b, err
+		}
+		logrus.Warnf("%v; retrying in %s", err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Kinds of governance findings.
+const (
+	findingOrphaned      = "orphaned project"
+	findingMissingPerson = "missing People entry"
+	findingFailed        = "failed validation"
+	findingRule          = "project rule violated"
+	findingOverExtended  = "over-extended maintainer"
+	findingGenerated     = "auto-generated People entry"
+)
+
+// finding is a governance problem detected during a run.
+type finding struct {
+	Kind    string `json:"kind"`
+	Project string `json:"project"`
+	Message string `json:"message"`
+}
+
+// trackingIssueTitle identifies the issue listing the findings of the last run.
+const trackingIssueTitle = "Governance problems found by maintainercollector"
+
+// governanceFindings lists the problems in the combined maintainers: projects
+// without maintainers, people listed without a People entry, maintainers of
+// too many projects and the projects whose MAINTAINERS file failed to load,
+// given by failures.
+func governanceFindings(m Maintainers, failures []projectFailure) []finding {
+	var findings []finding
+
+	for _, p := range m.Projects() {
+		if len(m.Org[p].People) == 0 {
+			findings = append(findings, finding{Kind: findingOrphaned, Project: p, Message: "no maintainers listed"})
+		}
+	}
+
+	for name, o := range m.Org {
+		for _, nick := range o.People {
+			if p, ok := m.People[nick]; !ok {
+				findings = append(findings, finding{Kind: findingMissingPerson, Project: name, Message: fmt.Sprintf("%s has no People entry", nick)})
+			} else if p.AutoGenerated {
+				findings = append(findings, finding{Kind: findingGenerated, Project: name, Message: fmt.Sprintf("%s has no People entry, one was synthesized from GitHub", nick)})
+			}
+		}
+	}
+
+	over := overExtended(m, config.MaxProjectsPerPerson)
+	for _, p := range m.Projects() {
+		for _, nick := range m.Org[p].People {
+			if n := len(over[nick]); n > 0 {
+				findings = append(findings, finding{Kind: findingOverExtended, Project: p,
+					Message: fmt.Sprintf("%s maintains %d projects, more than %d", nick, n, config.MaxProjectsPerPerson)})
+			}
+		}
+	}
+
+	for _, f := range failures {
+		findings = append(findings, finding{Kind: findingFailed, Project: f.Project, Message: f.Err.Error()})
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// sortFindings sorts findings by kind, project and message.
+func sortFindings(findings []finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		if findings[i].Project != findings[j].Project {
+			return findings[i].Project < findings[j].Project
+		}
+		return findings[i].Message < findings[j].Message
+	})
+}
+
+// overExtended returns the people maintaining more than max projects, with
+// the projects they maintain. A max of 0 disables the check.
+func overExtended(m Maintainers, max int) map[string][]string {
+	over := map[string][]string{}
+	if max <= 0 {
+		return over
+	}
+	for _, p := range m.Projects() {
+		for _, nick := range m.Org[p].People {
+			over[nick] = append(over[nick], p)
+		}
+	}
+	for nick, projects := range over {
+		if len(projects) <= max {
+			delete(over, nick)
+		}
+	}
+	return over
+}
+
+// fileFindings opens or updates the tracking issue in repo ("org/project")
+// with the given findings. Without findings, an open tracking issue is closed.
+func fileFindings(repo string, findings []finding) error {
+	org, project := getProjectOrg(repo)
+
+	var issues []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/issues?state=open&per_page=100", org, project), &issues); err != nil {
+		return fmt.Errorf("%s/%s: %v", org, project, err)
+	}
+	number := 0
+	for _, i := range issues {
+		if i.Title == trackingIssueTitle {
+			number = i.Number
+		}
+	}
+
+	body := new(bytes.Buffer)
+	if len(findings) == 0 {
+		fmt.Fprintln(body, "The last run of the maintainers collector found no governance problems.")
+	} else {
+		fmt.Fprintf(body, "The last run of the maintainers collector found %d governance problems:\n", len(findings))
+		kind := ""
+		for _, f := range findings {
+			if f.Kind != kind {
+				kind = f.Kind
+				fmt.Fprintf(body, "\n### %s\n\n", kind)
+			}
+			fmt.Fprintf(body, "- [ ] **%s**: %s\n", f.Project, f.Message)
+		}
+		fmt.Fprintln(body, "\nThis issue is updated by every run.")
+	}
+
+	switch {
+	case number == 0 && len(findings) == 0:
+		return nil
+	case number == 0:
+		var created struct {
+			HTMLURL string `json:"html_url"`
+		}
+		if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/issues", org, project), map[string]string{
+			"title": trackingIssueTitle,
+			"body":  body.String(),
+		}, &created); err != nil {
+			return fmt.Errorf("%s/%s: opening tracking issue failed: %v", org, project, err)
+		}
+		logrus.Infof("opened tracking issue %s", created.HTMLURL)
+	default:
+		update := map[string]string{"body": body.String()}
+		if len(findings) == 0 {
+			update["state"] = "closed"
+		}
+		if err := githubRequest("PATCH", fmt.Sprintf("/repos/%s/%s/issues/%d", org, project, number), update, nil); err != nil {
+			return fmt.Errorf("%s/%s: updating tracking issue #%d failed: %v", org, project, number, err)
+		}
+		logrus.Infof("updated tracking issue %s/%s#%d", org, project, number)
+	}
+	return nil
+}
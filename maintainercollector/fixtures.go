@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Fixtures are stored in a directory with one file per GitHub response:
+//
+//	raw/<org>/<project>/<branch>/<path>    raw files
+//	api/<path>[?<query>].json               GitHub API responses
+//
+// They are recorded with -record and replayed with -fixtures, which runs the
+// whole collect, merge and encode pipeline without network access.
+
+// fixturePath returns the file of the fixture for a request to u, relative to
+// the fixtures directory. raw tells whether u is a raw file.
+func fixturePath(u *url.URL, raw bool) string {
+	if raw {
+		return filepath.Join("raw", filepath.FromSlash(u.Path))
+	}
+	p := u.Path
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return filepath.Join("api", filepath.FromSlash(p)+".json")
+}
+
+// newFakeGitHub starts a server replaying the fixtures in dir. Raw files are
+// served under /raw and API responses under /api. Requests without fixture
+// fail with 404 Not Found; requests other than GET are accepted and answered
+// with an empty JSON object unless a fixture exists.
+func newFakeGitHub(dir string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := strings.HasPrefix(r.URL.Path, "/raw/")
+		u := *r.URL
+		if raw {
+			u.Path = strings.TrimPrefix(u.Path, "/raw")
+		} else {
+			u.Path = strings.TrimPrefix(u.Path, "/api")
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, fixturePath(&u, raw)))
+		switch {
+		case err == nil:
+			w.Write(b)
+		case r.Method != "GET":
+			logrus.Infof("fixtures: accepted %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		default:
+			logrus.Warnf("fixtures: no fixture for %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// useFakeGitHub points the collector at a fake GitHub replaying the fixtures
+// in dir. The returned function stops the server and restores the endpoints.
+func useFakeGitHub(dir string) func() {
+	srv := newFakeGitHub(dir)
+	rawUri, apiUri := ghRawUri, ghApiUri
+	ghRawUri, ghApiUri = srv.URL+"/raw", srv.URL+"/api"
+
+	return func() {
+		srv.Close()
+		ghRawUri, ghApiUri = rawUri, apiUri
+	}
+}
+
+// recorder is an http.RoundTripper saving successful GET responses from
+// GitHub as fixtures in dir.
+type recorder struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil || req.Method != "GET" || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	var raw bool
+	switch {
+	case strings.HasPrefix(req.URL.String(), ghRawUri):
+		raw = true
+	case strings.HasPrefix(req.URL.String(), ghApiUri):
+	default:
+		return resp, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	file := filepath.Join(r.dir, fixturePath(req.URL, raw))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		logrus.Errorf("fixtures: %v", err)
+		return resp, nil
+	}
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		logrus.Errorf("fixtures: %v", err)
+	}
+	return resp, nil
+}
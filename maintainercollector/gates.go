@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Gates are data quality thresholds checked by generate. Exceeding one makes
+// the run exit with an error, after writing its outputs, so that CI can
+// ratchet the quality of the data up over time. Unset thresholds are not
+// checked.
+type Gates struct {
+	// MaxWarnings is the number of warnings logged during the run.
+	MaxWarnings *int
+
+	// MaxMissingEmails is the number of people without an email address.
+	MaxMissingEmails *int
+
+	// MaxUnverifiedHandles is the number of people without a GitHub handle
+	// or whose handle does not exist on GitHub. Handles found to exist are
+	// recorded in the state file, and only checked again after
+	// handleRecheck.
+	MaxUnverifiedHandles *int
+}
+
+// handleRecheck is how long a GitHub handle found to exist is trusted
+// before being checked again.
+const handleRecheck = 7 * 24 * time.Hour
+
+// gateResult is the outcome of a quality gate, recorded in the run report.
+type gateResult struct {
+	Gate   string   `json:"gate"`
+	Max    int      `json:"max"`
+	Actual int      `json:"actual"`
+	Passed bool     `json:"passed"`
+	Items  []string `json:"items,omitempty"`
+
+	// Errors are the checks that could not be made. A gate with errors
+	// fails, since its actual value is unknown.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// warnings counts the warnings logged during the run.
+var warnings int64
+
+// warningCounter is a logrus hook counting warnings.
+type warningCounter struct{}
+
+func (warningCounter) Levels() []logrus.Level { return []logrus.Level{logrus.WarnLevel} }
+
+func (warningCounter) Fire(*logrus.Entry) error {
+	atomic.AddInt64(&warnings, 1)
+	return nil
+}
+
+func init() {
+	logrus.AddHook(warningCounter{})
+}
+
+// checkGates checks the gates of the configuration against m and records
+// the results in the run report.
+func checkGates(g Gates, m Maintainers) {
+	var results []gateResult
+	add := func(name string, max *int, items []string, actual int, errs ...string) {
+		if max == nil {
+			return
+		}
+		sort.Strings(items)
+		sort.Strings(errs)
+		results = append(results, gateResult{Gate: name, Max: *max, Actual: actual, Passed: actual <= *max && len(errs) == 0, Items: items, Errors: errs})
+	}
+
+	if g.MaxMissingEmails != nil {
+		var missing []string
+		for nick, p := range m.People {
+			if p.Email == "" {
+				missing = append(missing, nick)
+			}
+		}
+		add("missing emails", g.MaxMissingEmails, missing, len(missing))
+	}
+
+	if g.MaxUnverifiedHandles != nil {
+		var unverified, errs []string
+		for nick, p := range m.People {
+			if p.GitHub == "" {
+				unverified = append(unverified, nick)
+				continue
+			}
+			if runState.handleVerified(p.GitHub, handleRecheck) {
+				continue
+			}
+			err := githubGet("/users/"+p.GitHub, nil)
+			switch {
+			case err == nil:
+				runState.verifyHandle(p.GitHub)
+			case errorClass(err) == classMissing:
+				logrus.Debugf("%s: GitHub handle %s: %v", nick, p.GitHub, err)
+				unverified = append(unverified, nick)
+			default:
+				errs = append(errs, fmt.Sprintf("%s: GitHub handle %s: %v", nick, p.GitHub, err))
+			}
+		}
+		add("unverified handles", g.MaxUnverifiedHandles, unverified, len(unverified), errs...)
+	}
+
+	// counted last, so that warnings logged by the other gates count
+	add("warnings", g.MaxWarnings, nil, int(atomic.LoadInt64(&warnings)))
+
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			logrus.Errorf("quality gate %q could not be checked: %s", r.Gate, strings.Join(r.Errors, "; "))
+		} else if !r.Passed {
+			logrus.Errorf("quality gate %q failed: %d, maximum %d", r.Gate, r.Actual, r.Max)
+		}
+	}
+
+	report.mu.Lock()
+	report.Gates = results
+	report.mu.Unlock()
+}
+
+// failedGates returns the description of the gates that failed in this run.
+func (r *runReport) failedGates() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var failed []string
+	for _, g := range r.Gates {
+		if len(g.Errors) > 0 {
+			failed = append(failed, fmt.Sprintf("%s (%d checks failed)", g.Gate, len(g.Errors)))
+		} else if !g.Passed {
+			failed = append(failed, fmt.Sprintf("%s (%d > %d)", g.Gate, g.Actual, g.Max))
+		}
+	}
+	return failed
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnverifiedHandlesGate(t *testing.T) {
+	gh := answerGitHub(map[string]interface{}{
+		"GET /users/alice": map[string]string{"login": "alice"},
+		"GET /users/carol": &githubError{Method: "GET", Path: "/users/carol", StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"},
+	})
+	useMocks(t, gh, nil, nil, nil)
+	savedState, savedReport := runState, report
+	runState, report = &collectorState{Projects: map[string]*projectState{}}, &runReport{}
+	t.Cleanup(func() { runState, report = savedState, savedReport })
+
+	max := 1
+	m := Maintainers{People: map[string]Person{
+		"alice": {GitHub: "alice"},
+		"bob":   {GitHub: "bob"},
+		"carol": {GitHub: "carol"},
+		"dave":  {},
+	}}
+	checkGates(Gates{MaxUnverifiedHandles: &max}, m)
+
+	g := report.Gates[0]
+	if g.Actual != 2 || len(g.Items) != 2 || g.Items[0] != "bob" || g.Items[1] != "dave" {
+		t.Errorf("got %d unverified handles %v, want bob, whose handle is missing, and dave, who has none", g.Actual, g.Items)
+	}
+	if len(g.Errors) != 1 || g.Passed {
+		t.Errorf("got errors %v and passed %v, want the failed check of carol to fail the gate", g.Errors, g.Passed)
+	}
+
+	// alice is only checked once
+	checkGates(Gates{MaxUnverifiedHandles: &max}, m)
+	requests := 0
+	for _, r := range gh.RequestCalls {
+		if r.Path == "/users/alice" {
+			requests++
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests for alice, want 1", requests)
+	}
+}
@@ -22,6 +22,14 @@ func main() {
 	if err := generateFile(wd, "roles.toml", "roles"); err != nil {
 		panic(err)
 	}
+
+	if err := generateFile(wd, "openapi.json", "openapi"); err != nil {
+		panic(err)
+	}
+
+	if err := generateFile(wd, "console.html", "consoleui"); err != nil {
+		panic(err)
+	}
 }
 
 func generateFile(wd string, file string, target string) error {
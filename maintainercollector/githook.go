@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// maxHookPayload is the largest GitHub webhook payload accepted.
+const maxHookPayload = 25 << 20
+
+// deliveryTTL is how long the delivery IDs of GitHub webhooks are remembered
+// to reject replays.
+const deliveryTTL = 24 * time.Hour
+
+// deliveries remembers the IDs of the GitHub webhook deliveries received.
+type deliveries struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// first records id, and reports whether it was not seen within deliveryTTL.
+func (d *deliveries) first(id string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil {
+		d.seen = map[string]time.Time{}
+	}
+	for k, t := range d.seen {
+		if now.Sub(t) > deliveryTTL {
+			delete(d.seen, k)
+		}
+	}
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	d.seen[id] = now
+	return true
+}
+
+// validHookSignature reports whether the X-Hub-Signature-256 header of a
+// GitHub webhook signs payload with one of secrets. Several secrets are
+// accepted while rotating them.
+func validHookSignature(header string, payload []byte, secrets []string) bool {
+	if !strings.HasPrefix(header, "sha256=") {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil {
+		return false
+	}
+	for _, secret := range secrets {
+		want, _ := hex.DecodeString(signPayload(secret, payload))
+		if hmac.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveGitHubHook receives the GitHub webhooks of the repositories of the
+// tenant, and regenerates the combined file when a push changes a
+// MAINTAINERS file. Deliveries must be signed with one of the
+// GitHubWebhookSecrets of the configuration, and each delivery is only
+// accepted once.
+func (s *server) serveGitHubHook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxHookPayload))
+	if err != nil {
+		http.Error(w, "reading the payload failed", http.StatusBadRequest)
+		return
+	}
+	if !validHookSignature(r.Header.Get("X-Hub-Signature-256"), payload, s.config.GitHubWebhookSecrets) {
+		logrus.Warnf("%sGitHub webhook: invalid signature from %s", s.logPrefix(), r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	id := r.Header.Get("X-GitHub-Delivery")
+	if id == "" {
+		http.Error(w, "missing X-GitHub-Delivery header", http.StatusBadRequest)
+		return
+	}
+	if !s.deliveries.first(id, time.Now()) {
+		logrus.Warnf("%sGitHub webhook: replayed delivery %s", s.logPrefix(), id)
+		http.Error(w, "delivery already received", http.StatusConflict)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		w.Write([]byte("pong\n"))
+		return
+	case "push":
+	default:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var push struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Commits []struct {
+			Added    []string `json:"added"`
+			Modified []string `json:"modified"`
+			Removed  []string `json:"removed"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(payload, &push); err != nil {
+		http.Error(w, "invalid push payload", http.StatusBadRequest)
+		return
+	}
+	changed := false
+	for _, c := range push.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			changed = changed || containsFold(files, "MAINTAINERS")
+		}
+	}
+	if !changed {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	logrus.Infof("%sGitHub webhook: MAINTAINERS of %s changed, regenerating", s.logPrefix(), push.Repository.FullName)
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+		// a regeneration is already pending
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
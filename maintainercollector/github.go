@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/docker/opensource/maintainercollector/clients"
+)
+
+// ghApiUri is the base URL of the GitHub API.
+var ghApiUri = "https://api.github.com"
+
+// ghToken returns the token authenticating the requests to the GitHub API,
+// if any. Profiles may read it from elsewhere.
+var ghToken = func() string { return os.Getenv("GITHUB_TOKEN") }
+
+// githubAPI sends requests to the GitHub API. See package clientstest for a
+// mock.
+type githubAPI = clients.GitHub
+
+// conditionalAPI is implemented by the githubAPI clients supporting
+// conditional requests, which GitHub does not count against the rate limit
+// when the resource did not change.
+type conditionalAPI interface {
+	// GetIfNoneMatch sends a GET request to path, conditional on the
+	// resource having changed since etag if not empty, and decodes the JSON
+	// response into v. It returns the ETag of the resource, and whether it
+	// was not modified, in which case v is left untouched.
+	GetIfNoneMatch(path string, etag string, v interface{}) (string, bool, error)
+}
+
+// github is the GitHub API client used by all commands.
+var github githubAPI = githubClient{}
+
+// githubClient is the githubAPI talking to ghApiUri. Requests are
+// authenticated with the token returned by ghToken, if any.
+type githubClient struct{}
+
+func (c githubClient) Request(method string, path string, body interface{}, v interface{}) error {
+	_, err := c.send(method, path, body, nil, v)
+	return err
+}
+
+// GetIfNoneMatch implements conditionalAPI.
+func (c githubClient) GetIfNoneMatch(path string, etag string, v interface{}) (string, bool, error) {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	resp, err := c.send("GET", path, nil, header, v)
+	if err != nil {
+		return "", false, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return etag, true, nil
+	}
+	return resp.Header.Get("ETag"), false, nil
+}
+
+// send sends a request with the given extra headers and decodes the JSON
+// response into v (if v is not nil). Responses of 304 Not Modified are not
+// decoded. The returned response is closed.
+func (githubClient) send(method string, path string, body interface{}, header http.Header, v interface{}) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, ghApiUri+path, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := ghToken(); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &githubError{Method: method, Path: path, StatusCode: resp.StatusCode, Status: resp.Status,
+			RateLimited: resp.StatusCode == http.StatusTooManyRequests ||
+				resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"}
+	}
+
+	if v == nil {
+		return resp, nil
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// githubError is the error of a request answered with an error status.
+type githubError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+
+	// RateLimited is set if GitHub refused the request because of its
+	// rate limits.
+	RateLimited bool
+}
+
+func (e *githubError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Method, e.Path, e.Status)
+}
+
+// isRateLimited reports whether err is a request refused because of the
+// rate limits of GitHub.
+func isRateLimited(err error) bool {
+	e, ok := err.(*githubError)
+	return ok && e.RateLimited
+}
+
+// githubRequest sends a request through the github client.
+func githubRequest(method string, path string, body interface{}, v interface{}) error {
+	return github.Request(method, path, body, v)
+}
+
+// githubGetIfNoneMatch sends a conditional GET request through the github
+// client, see conditionalAPI, or a plain GET request if it does not support
+// them.
+func githubGetIfNoneMatch(path string, etag string, v interface{}) (string, bool, error) {
+	if c, ok := github.(conditionalAPI); ok {
+		return c.GetIfNoneMatch(path, etag, v)
+	}
+	return "", false, githubGet(path, v)
+}
+
+// githubGet is a shorthand for a GET request through githubRequest.
+func githubGet(path string, v interface{}) error {
+	return githubRequest("GET", path, nil, v)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// addGroups adds the committees and working groups from the configuration
+// and the governance file to the combined maintainers. Members are expected
+// to have a People entry.
+func addGroups(m *Maintainers) error {
+	committees := map[string]*Group{}
+	workingGroups := map[string]*Group{}
+	for name, g := range config.Committees {
+		committees[name] = g
+	}
+	for name, g := range config.WorkingGroups {
+		workingGroups[name] = g
+	}
+
+	if config.Governance != "" {
+		p := strings.SplitN(config.Governance, "/", 3)
+		if len(p) != 3 {
+			return fmt.Errorf("invalid Governance %q, expected org/project/path", config.Governance)
+		}
+		file, err := getRawFile(p[0], p[1], p[2])
+		if err != nil {
+			return err
+		}
+
+		var governance struct {
+			Committees    map[string]*Group
+			WorkingGroups map[string]*Group
+		}
+		if _, err := toml.Decode(string(file), &governance); err != nil {
+			return fmt.Errorf("%s: parsing failed: %v", config.Governance, err)
+		}
+
+		// the configuration takes precedence over the governance file
+		for name, g := range governance.Committees {
+			if _, ok := committees[name]; !ok {
+				committees[name] = g
+			}
+		}
+		for name, g := range governance.WorkingGroups {
+			if _, ok := workingGroups[name]; !ok {
+				workingGroups[name] = g
+			}
+		}
+	}
+
+	if len(committees) > 0 {
+		m.Committees = normalizeGroups(m, "Committees", committees)
+	}
+	if len(workingGroups) > 0 {
+		m.WorkingGroups = normalizeGroups(m, "WorkingGroups", workingGroups)
+	}
+	return nil
+}
+
+// normalizeGroups lowercases and sorts the members of each group, warning
+// about members without a People entry.
+func normalizeGroups(m *Maintainers, section string, groups map[string]*Group) map[string]*Group {
+	for name, g := range groups {
+		people := make([]string, len(g.People))
+		for i, nick := range g.People {
+			nick = strings.ToLower(nick)
+			if _, ok := m.People[nick]; !ok {
+				logrus.Warnf("%s.%s: %s has no People entry", section, name, nick)
+			}
+			people[i] = nick
+		}
+		groups[name] = &Group{Title: g.Title, Charter: g.Charter, Link: g.Link, People: removeDuplicates(people)}
+	}
+	return groups
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// unknownCompany is the column of the maintainers without a Company.
+const unknownCompany = "(unknown)"
+
+// crossTab counts maintainers by row and column.
+type crossTab struct {
+	Title   string
+	Rows    []string
+	Columns []string
+	Counts  map[string]map[string]int
+}
+
+func (t *crossTab) add(row, column string) {
+	if t.Counts[row] == nil {
+		t.Counts[row] = map[string]int{}
+	}
+	t.Counts[row][column]++
+}
+
+// maxCount returns the largest count of t.
+func (t *crossTab) maxCount() int {
+	max := 0
+	for _, row := range t.Counts {
+		for _, n := range row {
+			if n > max {
+				max = n
+			}
+		}
+	}
+	return max
+}
+
+// heatmapCmd implements the heatmap command.
+func heatmapCmd(args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	output := fs.String("o", "heatmap", "directory to write the CSV files and heatmap.html to")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: heatmap [options]\n\n"+
+			"Writes the maintainers of each project by company, and the projects of each person by GitHub org,\n"+
+			"as CSV files and an HTML heatmap.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	m := collectMaintainers()
+	tabs := []*crossTab{projectsByCompany(m), orgsByPerson(m)}
+	files := []string{"projects-by-company.csv", "orgs-by-person.csv"}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		return err
+	}
+	for i, t := range tabs {
+		b, err := encodeCrossTab(t)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(*output, files[i]), b, 0644); err != nil {
+			return err
+		}
+	}
+
+	html := new(bytes.Buffer)
+	if err := heatmapTemplate.Execute(html, tabs); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(*output, "heatmap.html"), html.Bytes(), 0644); err != nil {
+		return err
+	}
+	logrus.Infof("wrote the heatmap to %s", *output)
+	return nil
+}
+
+// projectsByCompany counts the maintainers of each project by company.
+// Companies are compared case-insensitively and named after their first
+// spelling, in the order of the nicks.
+func projectsByCompany(m Maintainers) *crossTab {
+	t := &crossTab{Title: "Maintainers by project and company", Rows: m.Projects(), Counts: map[string]map[string]int{}}
+
+	var nicks []string
+	for nick := range m.People {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+	spelling := map[string]string{}
+	company := map[string]string{}
+	for _, nick := range nicks {
+		c := strings.TrimPrefix(strings.TrimSpace(m.People[nick].Company), "@")
+		if c == "" {
+			continue
+		}
+		key := normalizeCompany(c)
+		if _, ok := spelling[key]; !ok {
+			spelling[key] = c
+		}
+		company[nick] = spelling[key]
+	}
+
+	columns := map[string]bool{}
+	for _, project := range t.Rows {
+		for _, nick := range removeDuplicates(m.Org[project].People) {
+			c, ok := company[nick]
+			if !ok {
+				c = unknownCompany
+			}
+			t.add(project, c)
+			columns[c] = true
+		}
+	}
+	t.Columns = sortedLabels(columns)
+	return t
+}
+
+// orgsByPerson counts the projects each person maintains in each GitHub org.
+func orgsByPerson(m Maintainers) *crossTab {
+	t := &crossTab{Title: "Projects by GitHub org and person", Counts: map[string]map[string]int{}}
+
+	orgs, columns := map[string]bool{}, map[string]bool{}
+	seen := map[string]bool{}
+	for _, p := range collectedProjects() {
+		org, project := getProjectOrg(p)
+		section := sectionName(project)
+		o, ok := m.Org[section]
+		if !ok || seen[org+"/"+section] {
+			continue
+		}
+		seen[org+"/"+section] = true
+		orgs[org] = true
+		for _, nick := range removeDuplicates(o.People) {
+			t.add(org, nick)
+			columns[nick] = true
+		}
+	}
+	t.Rows = sortedLabels(orgs)
+	t.Columns = sortedLabels(columns)
+	return t
+}
+
+// sortedLabels returns the sorted keys of set, unknownCompany last.
+func sortedLabels(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		if k != unknownCompany {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.ToLower(keys[i]) < strings.ToLower(keys[j]) })
+	if set[unknownCompany] {
+		keys = append(keys, unknownCompany)
+	}
+	return keys
+}
+
+// encodeCrossTab returns t as CSV, with a total per row.
+func encodeCrossTab(t *crossTab) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Write(append(append([]string{""}, t.Columns...), "total"))
+	for _, row := range t.Rows {
+		record, total := []string{row}, 0
+		for _, c := range t.Columns {
+			record = append(record, strconv.Itoa(t.Counts[row][c]))
+			total += t.Counts[row][c]
+		}
+		w.Write(append(record, strconv.Itoa(total)))
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+var heatmapTemplate = template.Must(template.New("heatmap").Funcs(template.FuncMap{
+	"cell": func(t *crossTab, row, column string) template.CSS {
+		max := t.maxCount()
+		if max == 0 || t.Counts[row][column] == 0 {
+			return ""
+		}
+		return template.CSS(fmt.Sprintf("background: rgba(220, 60, 30, %.2f)", 0.1+0.9*float64(t.Counts[row][column])/float64(max)))
+	},
+	"count": func(t *crossTab, row, column string) int { return t.Counts[row][column] },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Maintainers heatmap</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ddd; padding: 0.2em 0.5em; text-align: center; }
+th.row { text-align: left; }
+th.column { writing-mode: vertical-rl; transform: rotate(180deg); }
+</style>
+</head>
+<body>
+{{range $t := .}}
+<h2>{{$t.Title}}</h2>
+<table>
+<tr><th></th>{{range $t.Columns}}<th class="column">{{.}}</th>{{end}}</tr>
+{{range $row := $t.Rows}}<tr><th class="row">{{$row}}</th>{{range $column := $t.Columns}}<td style="{{cell $t $row $column}}">{{with count $t $row $column}}{{.}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// historyFile is the history store, given with -history: a file of JSON
+// lines, each a snapshot of the maintainers of every project. A snapshot is
+// appended by each run changing them. It is written like the other files
+// holding personal data: readable only by its owner, and encrypted if
+// -key-file is given.
+var historyFile string
+
+// snapshot is the maintainers of every project at a point in time, and the
+// governance findings of the run that recorded it.
+type snapshot struct {
+	Time     time.Time           `json:"time"`
+	RunID    string              `json:"run_id,omitempty"`
+	Projects map[string][]string `json:"projects"`
+	Findings []finding           `json:"findings,omitempty"`
+}
+
+// loadHistory reads the snapshots of the history store at path, oldest
+// first. A missing file is an empty history.
+func loadHistory(path string) ([]snapshot, error) {
+	b, err := files.Load(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history []snapshot
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		history = append(history, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Time.Before(history[j].Time) })
+	return history, nil
+}
+
+// recordHistory appends a snapshot of m and findings to the history store
+// at path, unless neither changed since the last snapshot. The projects of
+// failures missing from m keep their roster of the last snapshot: a failed
+// fetch is not a removal of their maintainers. The snapshot is identified by
+// the -run-id of the run, or else by its time.
+func recordHistory(path string, m Maintainers, findings []finding, failures []projectFailure) error {
+	history, err := loadHistory(path)
+	if err != nil {
+		return err
+	}
+
+	s := snapshot{Time: time.Now().UTC(), RunID: runID, Projects: map[string][]string{}, Findings: findings}
+	if s.RunID == "" {
+		s.RunID = s.Time.Format("20060102T150405Z")
+	}
+	for _, p := range m.Projects() {
+		s.Projects[p] = m.Org[p].People
+	}
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		for _, f := range failures {
+			if _, ok := s.Projects[f.Section]; ok {
+				continue
+			}
+			if people, ok := last.Projects[f.Section]; ok {
+				s.Projects[f.Section] = people
+			}
+		}
+	}
+
+	if len(history) > 0 && sameRosters(history[len(history)-1], s) && sameFindings(history[len(history)-1].Findings, s.Findings) {
+		return nil
+	}
+
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	// the store may be encrypted, so it is rewritten rather than appended to
+	b, err := files.Load(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(b) > 0 && !bytes.HasSuffix(b, []byte("\n")) {
+		b = append(b, '\n')
+	}
+	return files.Save(path, append(append(b, line...), '\n'), 0600)
+}
+
+// sameRosters reports whether a and b list the same maintainers.
+func sameRosters(a, b snapshot) bool {
+	if len(a.Projects) != len(b.Projects) {
+		return false
+	}
+	for p, people := range a.Projects {
+		others, ok := b.Projects[p]
+		if !ok {
+			return false
+		}
+		added, removed := diffNicks(people, others)
+		if len(added) > 0 || len(removed) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFindings reports whether a and b hold the same findings, in any order.
+func sameFindings(a, b []finding) bool {
+	added, resolved := diffFindings(a, b)
+	return len(added) == 0 && len(resolved) == 0
+}
+
+// diffFindings returns the findings of after missing from before, and of
+// before missing from after.
+func diffFindings(before, after []finding) (added, resolved []finding) {
+	in := func(list []finding, f finding) bool {
+		for _, g := range list {
+			if g == f {
+				return true
+			}
+		}
+		return false
+	}
+	for _, f := range after {
+		if !in(before, f) {
+			added = append(added, f)
+		}
+	}
+	for _, f := range before {
+		if !in(after, f) {
+			resolved = append(resolved, f)
+		}
+	}
+	return added, resolved
+}
+
+// quarterChurn is the number of maintainers added to and removed from a
+// project during a quarter.
+type quarterChurn struct {
+	Quarter string `json:"quarter"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// quarter returns the quarter of t, as "2006-Q1".
+func quarter(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// churnByQuarter returns the maintainers added to and removed from project
+// in each quarter of the history, oldest first. The first snapshot is the
+// baseline; a project appearing later counts its maintainers as added.
+func churnByQuarter(history []snapshot, project string) []quarterChurn {
+	var churn []quarterChurn
+	for i := 1; i < len(history); i++ {
+		added, removed := diffNicks(history[i-1].Projects[project], history[i].Projects[project])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		q := quarter(history[i].Time)
+		if len(churn) == 0 || churn[len(churn)-1].Quarter != q {
+			churn = append(churn, quarterChurn{Quarter: q})
+		}
+		churn[len(churn)-1].Added += len(added)
+		churn[len(churn)-1].Removed += len(removed)
+	}
+	return churn
+}
+
+// historyProjects returns the sorted names of the projects of the history.
+func historyProjects(history []snapshot) []string {
+	seen := map[string]bool{}
+	var projects []string
+	for _, s := range history {
+		for p := range s.Projects {
+			if !seen[p] {
+				seen[p] = true
+				projects = append(projects, p)
+			}
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
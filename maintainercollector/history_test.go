@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	saved := encryptionKey
+	encryptionKey = []byte(strings.Repeat("k", 32))
+	t.Cleanup(func() { encryptionKey = saved })
+
+	for _, m := range []Maintainers{roster("cli", "alice"), roster("cli", "alice"), roster("cli", "alice", "bob")} {
+		if err := recordHistory(path, m, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want 0600", fi.Mode().Perm())
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), encryptedMagic) || strings.Contains(string(b), "alice") {
+		t.Error("the history store is not encrypted")
+	}
+
+	history, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || len(history[1].Projects["cli"]) != 2 {
+		t.Errorf("got %d snapshots %+v, want the two distinct rosters", len(history), history)
+	}
+}
+
+// TestRecordHistoryFailure checks that a project failing to load keeps its
+// roster in the history, rather than being recorded as gone.
+func TestRecordHistoryFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	m := Maintainers{Org: map[string]*Org{"cli": {People: []string{"alice"}}, "compose": {People: []string{"bob"}}}}
+	if err := recordHistory(path, m, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	failures := []projectFailure{{Org: "docker", Project: "compose", Err: errors.New("fetching MAINTAINERS failed"), Section: "compose"}}
+	if err := recordHistory(path, roster("cli", "alice", "carol"), nil, failures); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(history))
+	}
+	if people := history[1].Projects["compose"]; len(people) != 1 || people[0] != "bob" {
+		t.Errorf("got compose maintained by %v after its failure, want bob", people)
+	}
+	if people := history[1].Projects["cli"]; len(people) != 2 {
+		t.Errorf("got cli maintained by %v, want alice and carol", people)
+	}
+}
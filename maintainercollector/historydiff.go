@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runDiff is the difference between two runs of the history store.
+type runDiff struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Projects []projectDiff `json:"projects"`
+	Added    []finding     `json:"new_findings"`
+	Resolved []finding     `json:"resolved_findings"`
+}
+
+// projectDiff is the difference between the maintainers of a project in two
+// runs. Status is "added", "removed" or "changed".
+type projectDiff struct {
+	Project string   `json:"project"`
+	Status  string   `json:"status"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// historyCmd implements the history command.
+func historyCmd(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	format := fs.String("format", "text", "output format, text or json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: history [options] list | diff <run> <run>\n\n"+
+			"Lists the runs of the history store given with -history, or compares the rosters and findings of two.\n"+
+			"A run is given by its -run-id, its number in the list, or a date (2006-01-02) or time (RFC 3339)\n"+
+			"it was in effect at.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if historyFile == "" {
+		return fmt.Errorf("history: requires -history")
+	}
+	history, err := loadHistory(historyFile)
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tRUN\tTIME\tPROJECTS\tFINDINGS")
+		for i, s := range history {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\n", i+1, s.RunID, s.Time.Format(time.RFC3339), len(s.Projects), len(s.Findings))
+		}
+		return w.Flush()
+	case "diff":
+		if fs.NArg() != 3 {
+			fs.Usage()
+			return fmt.Errorf("history: diff expects two runs")
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("history: expected list or diff")
+	}
+
+	from, err := findRun(history, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	to, err := findRun(history, fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	d := diffRuns(from, to)
+	d.From, d.To = fs.Arg(1), fs.Arg(2)
+
+	if *format == "json" {
+		b, err := json.MarshalIndent(d, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(b, '\n'))
+		return err
+	}
+
+	fmt.Printf("# %s (%s) -> %s (%s)\n\n", d.From, from.Time.Format(time.RFC3339), d.To, to.Time.Format(time.RFC3339))
+	if len(d.Projects) == 0 {
+		fmt.Printf("No roster changes.\n")
+	}
+	for _, p := range d.Projects {
+		fmt.Printf("%s (%s)\n", p.Project, p.Status)
+		for _, nick := range p.Added {
+			fmt.Printf("  + %s\n", nick)
+		}
+		for _, nick := range p.Removed {
+			fmt.Printf("  - %s\n", nick)
+		}
+	}
+	fmt.Println()
+	if len(d.Added) == 0 && len(d.Resolved) == 0 {
+		fmt.Printf("No audit changes.\n")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "\tKIND\tPROJECT\tMESSAGE")
+	for _, f := range d.Added {
+		fmt.Fprintf(w, "new\t%s\t%s\t%s\n", f.Kind, f.Project, f.Message)
+	}
+	for _, f := range d.Resolved {
+		fmt.Fprintf(w, "resolved\t%s\t%s\t%s\n", f.Kind, f.Project, f.Message)
+	}
+	return w.Flush()
+}
+
+// findRun returns the snapshot of the history identified by ref: the last
+// one recorded with that run ID, the one at that position in the history,
+// counting from 1, or the one in effect at that date or time.
+func findRun(history []snapshot, ref string) (snapshot, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].RunID == ref {
+			return history[i], nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(history) {
+			return snapshot{}, fmt.Errorf("history: run %d out of range, the history store has %d runs", n, len(history))
+		}
+		return history[n-1], nil
+	}
+	t, err := time.Parse("2006-01-02", ref)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, ref); err != nil {
+			return snapshot{}, fmt.Errorf("history: unknown run %q", ref)
+		}
+	}
+	s, ok := snapshotAt(history, t)
+	if !ok {
+		return snapshot{}, fmt.Errorf("history: the history store has no snapshot before %s", t.Format(time.RFC3339))
+	}
+	return s, nil
+}
+
+// diffRuns returns the roster and findings differences from one snapshot to
+// another, projects sorted by name.
+func diffRuns(from, to snapshot) runDiff {
+	d := runDiff{Projects: []projectDiff{}}
+	names := map[string]bool{}
+	for p := range from.Projects {
+		names[p] = true
+	}
+	for p := range to.Projects {
+		names[p] = true
+	}
+	var sorted []string
+	for p := range names {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		before, wasTracked := from.Projects[p]
+		after, isTracked := to.Projects[p]
+		pd := projectDiff{Project: p, Status: "changed"}
+		switch {
+		case !wasTracked:
+			pd.Status = "added"
+		case !isTracked:
+			pd.Status = "removed"
+		}
+		pd.Added, pd.Removed = diffNicks(before, after)
+		if pd.Status == "changed" && len(pd.Added) == 0 && len(pd.Removed) == 0 {
+			continue
+		}
+		d.Projects = append(d.Projects, pd)
+	}
+
+	d.Added, d.Resolved = diffFindings(from.Findings, to.Findings)
+	if d.Added == nil {
+		d.Added = []finding{}
+	}
+	if d.Resolved == nil {
+		d.Resolved = []finding{}
+	}
+	sortFindings(d.Added)
+	sortFindings(d.Resolved)
+	return d
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// IdentityProvider is a system people are looked up in by -enrich-profiles
+// to complete their People entry.
+type IdentityProvider struct {
+	// Type is "github", "gitlab" or "directory".
+	Type string
+
+	// URL is the base URL of a GitLab instance, https://gitlab.com by
+	// default.
+	URL string
+
+	// Usernames maps the nicks of people to their GitLab username. Other
+	// people are looked up by their GitHub handle, or their nick, as GitLab
+	// username, and the profile found is only used if its public email is
+	// the email of their People entry: the same username on GitLab may
+	// belong to someone else.
+	Usernames map[string]string
+
+	// TokenEnv is the environment variable holding the GitLab token, if
+	// any.
+	TokenEnv string
+
+	// Path is the CSV file of a directory, such as an employee directory
+	// exported from LDAP. Command, instead, is a program printing the CSV,
+	// such as a wrapper of ldapsearch, run with Timeout. The first line
+	// names the columns, among nick, github, email, name and company.
+	Path    string
+	Command []string
+	Timeout string
+
+	// Key is the column of the directory people are matched by: "github"
+	// (the default), "nick" or "email".
+	Key string
+}
+
+// identity is what a provider knows about a person.
+type identity struct {
+	Name, Email, Company string
+}
+
+// identityProvider is the implementation of an IdentityProvider.
+type identityProvider interface {
+	// lookup returns the identity of a person, if known. Failures are
+	// logged.
+	lookup(nick string, p Person) (identity, bool)
+
+	// finish is called once all people were looked up.
+	finish()
+}
+
+// check returns an error if the settings of p are invalid.
+func (p IdentityProvider) check() error {
+	if len(p.Usernames) > 0 && p.Type != "gitlab" {
+		return fmt.Errorf("%s: Usernames is only supported by gitlab", p.Type)
+	}
+	switch p.Type {
+	case "github", "gitlab":
+	case "directory":
+		if (p.Path == "") == (len(p.Command) == 0) {
+			return fmt.Errorf("directory: exactly one of Path and Command is required")
+		}
+		switch p.Key {
+		case "", "github", "nick", "email":
+		default:
+			return fmt.Errorf("directory: unknown Key %q", p.Key)
+		}
+	default:
+		return fmt.Errorf("unknown Type %q, expected github, gitlab or directory", p.Type)
+	}
+	return nil
+}
+
+// newIdentityProviders returns the implementations of the providers, or of
+// GitHub if there are none.
+func newIdentityProviders(providers []IdentityProvider) ([]identityProvider, error) {
+	if len(providers) == 0 {
+		return []identityProvider{&githubProvider{}}, nil
+	}
+
+	var list []identityProvider
+	for _, p := range providers {
+		switch p.Type {
+		case "github":
+			list = append(list, &githubProvider{})
+		case "gitlab":
+			g := &gitlabProvider{url: strings.TrimSuffix(p.URL, "/"), usernames: map[string]string{}}
+			for nick, username := range p.Usernames {
+				g.usernames[strings.ToLower(nick)] = username
+			}
+			if g.url == "" {
+				g.url = "https://gitlab.com"
+			}
+			if p.TokenEnv != "" {
+				g.token = os.Getenv(p.TokenEnv)
+			}
+			list = append(list, g)
+		case "directory":
+			d, err := loadDirectory(p)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+// gitlabProvider looks people up by their GitLab profile.
+type gitlabProvider struct {
+	url, token string
+
+	// usernames maps lowercased nicks to GitLab usernames.
+	usernames map[string]string
+}
+
+func (g *gitlabProvider) lookup(nick string, p Person) (identity, bool) {
+	username, mapped := g.usernames[strings.ToLower(nick)]
+	if !mapped {
+		// without a mapping, only a profile with the same email is
+		// known to be the person's
+		if p.Email == "" {
+			return identity{}, false
+		}
+		username = p.GitHub
+		if username == "" {
+			username = nick
+		}
+	}
+
+	req, err := http.NewRequest("GET", g.url+"/api/v4/users?username="+url.QueryEscape(username), nil)
+	if err != nil {
+		return identity{}, false
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logrus.Warnf("%s: fetching the GitLab profile of %s failed: %v", nick, username, err)
+		return identity{}, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logrus.Warnf("%s: fetching the GitLab profile of %s failed: %s", nick, username, resp.Status)
+		return identity{}, false
+	}
+
+	var users []struct {
+		Name         string `json:"name"`
+		PublicEmail  string `json:"public_email"`
+		Organization string `json:"organization"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil || len(users) == 0 {
+		return identity{}, false
+	}
+	u := users[0]
+	if !mapped && !strings.EqualFold(u.PublicEmail, p.Email) {
+		logrus.Debugf("%s: the GitLab profile of %s does not have the email %s, ignoring it", nick, username, p.Email)
+		return identity{}, false
+	}
+	return identity{Name: u.Name, Email: u.PublicEmail, Company: u.Organization}, true
+}
+
+func (g *gitlabProvider) finish() {}
+
+// directoryProvider looks people up in a CSV directory.
+type directoryProvider struct {
+	key    string
+	people map[string]identity
+}
+
+// loadDirectory reads the directory of p.
+func loadDirectory(p IdentityProvider) (*directoryProvider, error) {
+	var b []byte
+	var err error
+	if p.Path != "" {
+		b, err = ioutil.ReadFile(p.Path)
+	} else {
+		b, err = runPlugin("directory", p.Command, p.Timeout, nil, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading the directory failed: %v", err)
+	}
+
+	d := &directoryProvider{key: p.Key, people: map[string]identity{}}
+	if d.key == "" {
+		d.key = "github"
+	}
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing the directory failed: %v", err)
+	}
+	if len(records) == 0 {
+		return d, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns[d.key]; !ok {
+		return nil, fmt.Errorf("the directory has no %s column", d.key)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	for _, record := range records[1:] {
+		if key := strings.ToLower(field(record, d.key)); key != "" {
+			d.people[key] = identity{Name: field(record, "name"), Email: field(record, "email"), Company: field(record, "company")}
+		}
+	}
+	return d, nil
+}
+
+func (d *directoryProvider) lookup(nick string, p Person) (identity, bool) {
+	key := nick
+	switch d.key {
+	case "github":
+		key = p.GitHub
+	case "email":
+		key = p.Email
+	}
+	id, ok := d.people[strings.ToLower(key)]
+	return id, ok && key != ""
+}
+
+func (d *directoryProvider) finish() {}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabLookup(t *testing.T) {
+	profiles := map[string]map[string]string{
+		"alice":   {"name": "Alice", "public_email": "alice@example.com"},
+		"bob":     {"name": "Someone else", "public_email": "bob@elsewhere.example"},
+		"carol-g": {"name": "Carol", "organization": "Example"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var users []map[string]string
+		if u, ok := profiles[r.URL.Query().Get("username")]; ok {
+			users = append(users, u)
+		}
+		json.NewEncoder(w).Encode(users)
+	}))
+	defer srv.Close()
+
+	providers, err := newIdentityProviders([]IdentityProvider{{Type: "gitlab", URL: srv.URL, Usernames: map[string]string{"Carol": "carol-g"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := providers[0]
+	for _, tt := range []struct {
+		nick   string
+		person Person
+		want   string
+	}{
+		// same username and email
+		{"alice", Person{GitHub: "alice", Email: "Alice@example.com"}, "Alice"},
+		// same username, but another email
+		{"bob", Person{GitHub: "bob", Email: "bob@example.com"}, ""},
+		// no email to check the profile against
+		{"alice", Person{GitHub: "alice"}, ""},
+		// mapped explicitly
+		{"carol", Person{GitHub: "carol"}, "Carol"},
+	} {
+		id, ok := g.lookup(tt.nick, tt.person)
+		if ok != (tt.want != "") || id.Name != tt.want {
+			t.Errorf("%s %+v: got %+v, %v, want the name %q", tt.nick, tt.person, id, ok, tt.want)
+		}
+	}
+
+	if err := (IdentityProvider{Type: "github", Usernames: map[string]string{"a": "b"}}).check(); err == nil {
+		t.Error("Usernames accepted for github")
+	}
+}
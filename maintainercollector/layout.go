@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+// Layouts of the combined MAINTAINERS file, chosen with -layout.
+const (
+	// layoutCanonical is the current layout, with every section and key
+	// the collector knows about. It evolves with the collector.
+	layoutCanonical = "canonical"
+
+	// layoutLegacy reproduces the layout of the original combined file,
+	// which downstream parsers expect: the Org section only lists the
+	// People of each project, and People entries only have a Name, Email
+	// and GitHub key.
+	layoutLegacy = "legacy"
+)
+
+// outputLayout is the layout of the combined MAINTAINERS file.
+var outputLayout = layoutCanonical
+
+// legacyMaintainers is the combined file in the legacy layout. Its keys and
+// their casing are frozen; do not add fields.
+type legacyMaintainers struct {
+	Org    map[string]legacyOrg    `toml:"Org"`
+	People map[string]legacyPerson `toml:"People"`
+}
+
+type legacyOrg struct {
+	People []string `toml:"People"`
+}
+
+type legacyPerson struct {
+	Name   string `toml:"Name"`
+	Email  string `toml:"Email"`
+	GitHub string `toml:"GitHub"`
+}
+
+// checkLayout returns an error if layout is not a known layout.
+func checkLayout(layout string) error {
+	if layout != layoutCanonical && layout != layoutLegacy {
+		return fmt.Errorf("unknown layout %q, expected %s or %s", layout, layoutCanonical, layoutLegacy)
+	}
+	return nil
+}
+
+// inLayout returns the value encoding m in the given layout.
+func inLayout(m Maintainers, layout string) interface{} {
+	if layout != layoutLegacy {
+		return m
+	}
+	l := legacyMaintainers{Org: map[string]legacyOrg{}, People: map[string]legacyPerson{}}
+	for name, o := range m.Org {
+		l.Org[name] = legacyOrg{People: o.People}
+	}
+	for nick, p := range m.People {
+		l.People[nick] = legacyPerson{Name: p.Name, Email: p.Email, GitHub: p.GitHub}
+	}
+	return l
+}
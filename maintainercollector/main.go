@@ -2,20 +2,23 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/Sirupsen/logrus"
 )
 
 const (
-	defaultOrg = "docker"
-	ghRawUri   = "https://raw.githubusercontent.com"
-	head       = `#
+	head = `#
 # THIS FILE IS AUTOGENERATED; SEE "./maintainercollector"!
 #
 # Docker projects maintainers file
@@ -31,6 +34,15 @@ const (
 )
 
 var (
+	// defaultOrg is the organization of the projects given without one.
+	defaultOrg = "docker"
+
+	// writeJSON makes generate also write MAINTAINERS.json, set with -json.
+	writeJSON bool
+
+	// ghRawUri serves raw files from GitHub repositories, see also ghApiUri.
+	ghRawUri = "https://raw.githubusercontent.com"
+
 	projects = []string{
 		"boot2docker",
 		"cli",
@@ -75,83 +87,309 @@ var (
 
 //go:generate go run generate.go
 
+// commands maps the name of each command, other than generate, to its
+// implementation. Commands receive the arguments following their name.
+var commands = map[string]func(args []string) error{
+	"action":              actionCmd,
+	"audit-emails":        auditEmailsCmd,
+	"branches":            branchesCmd,
+	"browse":              browseCmd,
+	"churn":               churnCmd,
+	"client":              clientCmd,
+	"dashboard":           dashboardCmd,
+	"heatmap":             heatmapCmd,
+	"history":             historyCmd,
+	"missing-maintainers": missingMaintainersCmd,
+	"nominate":            nominateCmd,
+	"onboard":             onboardCmd,
+	"projects":            projectsCmd,
+	"propose-removals":    proposeRemovalsCmd,
+	"prune":               pruneCmd,
+	"query":               queryCmd,
+	"quorum":              quorumCmd,
+	"release":             releaseCmd,
+	"security-routing":    securityRoutingCmd,
+	"serve":               serveCmd,
+	"spof":                spofCmd,
+	"stats":               statsCmd,
+	"sync-teams":          syncTeamsCmd,
+	"verify-contacts":     verifyContactsCmd,
+	"votes":               votesCmd,
+}
+
 func main() {
-	// initialize the project MAINTAINERS file
-	projectMaintainers := Maintainers{
-		Org:    map[string]*Org{},
-		People: map[string]Person{},
+	configFile := flag.String("config", "", "path to the configuration file")
+	fixtures := flag.String("fixtures", "", "serve GitHub requests from the fixtures in this directory instead of the network")
+	record := flag.String("record", "", "record the responses of GitHub as fixtures in this directory")
+	stateFile := flag.String("state", "", "path to the file keeping the state of the collector between runs")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file")
+	memProfile := flag.String("memprofile", "", "write a memory profile to this file when done")
+	flag.StringVar(&cacheDir, "cache", "", "directory caching the MAINTAINERS file of each project; with -state, only changed files are fetched again")
+	flag.BoolVar(&useStale, "stale", false, "use the cached MAINTAINERS file of projects that fail to load (requires -cache)")
+	flag.StringVar(&historyFile, "history", "", "history store recording the maintainers of each project after each change")
+	reportFile := flag.String("report", "", "write a JSON report of the run to this file")
+	flag.BoolVar(&compressArtifacts, "gzip", false, "also write a gzip compressed copy of the generated files")
+	flag.StringVar(&outputLayout, "layout", layoutCanonical, "layout of the combined MAINTAINERS file: canonical, or legacy for the sections and keys of the original file only")
+	flag.BoolVar(&writeJSON, "json", false, "also write the combined maintainers as MAINTAINERS.json")
+	flag.BoolVar(&writeEmailIndex, "email-index", false, "also write MAINTAINERS.by-email.json, mapping each email address to nicks and their projects")
+	flag.BoolVar(&fallbackPeople, "fallback-people", false, "synthesize the People entry of maintainers without one from their GitHub profile and commits")
+	flag.BoolVar(&enrichProfiles, "enrich-profiles", false, "complete the name, email and company of people from the IdentityProviders of the configuration, GitHub by default; with -state, GitHub progress is checkpointed and resumed")
+	flag.DurationVar(&enrichDelay, "enrich-delay", enrichDelay, "delay between two GitHub profile requests")
+	flag.IntVar(&enrichBudget, "enrich-budget", 0, "maximum number of GitHub profiles fetched per run, 0 for no limit")
+	userAgent := flag.String("user-agent", defaultUserAgent(), "User-Agent sent with every HTTP request")
+	flag.StringVar(&runID, "run-id", "", "identifier of the run, sent with every HTTP request in the "+runIDHeader+" header")
+	source := flag.String("source", "raw", "source tried first to fetch MAINTAINERS files, raw or api; the other one is the fallback")
+	profile := flag.String("profile", "", "use the settings of this profile from the profiles file")
+	profilesFile := flag.String("profiles", defaultProfilesFile(), "path to the profiles file")
+	keyFile := flag.String("key-file", "", "file holding the hex encoded AES-256 key encrypting the cache, the state file, the verification store and the history store")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *profile != "" {
+		p, err := loadProfile(*profilesFile, *profile)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err := p.apply(configFile); err != nil {
+			logrus.Fatalf("profile %s: %v", *profile, err)
+		}
 	}
 
-	// initialize Curators
-	projectMaintainers.Org["Curators"] = &Org{}
-	projectMaintainers.Org["Docs maintainers"] = &Org{}
+	if err := loadConfig(*configFile); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := checkLayout(outputLayout); err != nil {
+		logrus.Fatalf("-layout: %v", err)
+	}
+
+	f, err := newFallbackFetcher(*source)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	rawFiles = f
 
-	// parse the MAINTAINERS file for each repo
-	for _, p := range projects {
-		org, project := getProjectOrg(p)
-		maintainers, err := getMaintainers(org, project)
+	if *keyFile != "" {
+		if err := loadEncryptionKey(*keyFile); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	if *stateFile != "" {
+		s, err := loadState(*stateFile)
 		if err != nil {
-			logrus.Errorf("%s: parsing MAINTAINERS file failed: %v", project, err)
-			continue
+			logrus.Fatalf("%s: %v", *stateFile, err)
 		}
+		runState = s
+		statePath = *stateFile
+	} else if cacheDir != "" {
+		logrus.Warn("-cache without -state fetches every project again")
+	}
+	if useStale && cacheDir == "" {
+		logrus.Fatal("-stale requires -cache")
+	}
 
-		p := &Org{}
-		if maintainers.Organization.Maintainers != nil {
-			p.People = maintainers.Organization.Maintainers.People
-		} else if maintainers.Organization.CoreMaintainers != nil {
-			// create the Org object for the project
-			p.People = maintainers.Organization.CoreMaintainers.People
-			//p := &Org{
-			//	// Repo: fmt.Sprintf("https://github.com/%s/%s", org, project),
-			//	// TODO: change this to:
-			//	// People: maintainers.Org["Core maintainers"].People,
-			//	// once MaintainersDepreciated is removed.
-			//	People: maintainers.Organization.CoreMaintainers.People,
-			//}
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			logrus.Fatal(err)
 		}
+		defer pprof.StopCPUProfile()
+	}
 
-		// lowercase all maintainers nicks for consistency
-		for i, n := range p.People {
-			p.People[i] = strings.ToLower(n)
+	switch {
+	case *fixtures != "" && *record != "":
+		logrus.Fatal("-fixtures and -record are mutually exclusive")
+	case *fixtures != "":
+		stop := useFakeGitHub(*fixtures)
+		defer stop()
+	case *record != "":
+		http.DefaultClient.Transport = &recorder{dir: *record, next: http.DefaultTransport}
+	}
+	tagClient(http.DefaultClient, *userAgent, runID)
+	tagClient(webhookClient, *userAgent, runID)
+
+	cmd := flag.Arg(0)
+	if cmd == "" || cmd == "generate" {
+		generate()
+	} else {
+		run, ok := commands[cmd]
+		if !ok {
+			logrus.Fatalf("unknown command %q", cmd)
+		}
+		if err := run(flag.Args()[1:]); err != nil {
+			logrus.Fatal(err)
 		}
-		sort.Strings(p.People)
+	}
 
-		projectMaintainers.Org[project] = p
+	if *stateFile != "" {
+		if err := runState.save(*stateFile); err != nil {
+			logrus.Fatalf("%s: %v", *stateFile, err)
+		}
+	}
 
-		if maintainers.Organization.DocsMaintainers != nil {
-			projectMaintainers.Org["Docs maintainers"].People = append(projectMaintainers.Org["Docs maintainers"].People, maintainers.Organization.DocsMaintainers.People...)
+	if *reportFile != "" {
+		if err := report.save(*reportFile); err != nil {
+			logrus.Fatalf("%s: %v", *reportFile, err)
 		}
+	}
 
-		if maintainers.Organization.Curators != nil {
-			projectMaintainers.Org["Curators"].People = append(projectMaintainers.Org["Curators"].People, maintainers.Organization.Curators.People...)
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			logrus.Fatal(err)
 		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	if failed := report.failedGates(); len(failed) > 0 {
+		pprof.StopCPUProfile()
+		logrus.Fatalf("quality gates failed: %s", strings.Join(failed, ", "))
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: %s [options] [command] [arguments]
 
-		// iterate through the people and add them to compiled list
-		for nick, person := range maintainers.People {
-			projectMaintainers.People[strings.ToLower(nick)] = person
+Commands:
+    generate        write the combined MAINTAINERS file (default)
+    action          validate the MAINTAINERS file of a repository in a GitHub Actions workflow
+    audit-emails    compare People emails with commit author emails
+    branches        report release branches whose maintainers diverged from the default branch
+    browse          explore the combined maintainers interactively
+    churn           report the maintainers added and removed per project and quarter
+    client          generate a Go or TypeScript client of the serve API, or its OpenAPI spec
+    dashboard       render the history store as an HTML dashboard with trend charts
+    heatmap         write maintainer counts by project and company, and by GitHub org and person, as CSV and HTML
+    history         list the runs of the history store, or diff the rosters and findings of two runs
+    missing-maintainers
+                    report active repositories without a MAINTAINERS file, optionally opening issues
+    nominate        open a pull request adding a maintainer to a project
+    onboard         write the onboarding packet of a new maintainer
+    projects        list the tracked projects and their status
+    propose-removals
+                    propose removing maintainers inactive for too long
+    prune           suggest removing archived, deleted or empty projects from the project list
+    query           answer who maintained a project, or what a person maintained, at a past date
+    quorum          evaluate the voting rule of a project against a list of approvals
+    release         bundle the generated files, checksums and signature into a versioned release
+    security-routing
+                    write the security contacts of each project and check for gaps
+    serve           regenerate the combined MAINTAINERS file periodically and serve it
+    spof            report people and projects that are single points of failure
+    stats           report maintainer counts and response times per project
+    sync-teams      make the GitHub teams of each project match its maintainers
+    verify-contacts
+                    email maintainers a link verifying their contact address
+    votes           report the votes on open pull requests changing MAINTAINERS
+
+Options:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// generate collects the MAINTAINERS files of all projects and writes the
+// combined result to ./MAINTAINERS.
+func generate() {
+	c, err := runPipeline("")
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	projectMaintainers, file := c.Maintainers, c.File
+
+	report.Findings = c.Findings
+	if config.Issues != "" {
+		if err := fileFindings(config.Issues, report.Findings); err != nil {
+			logrus.Errorf("filing governance findings failed: %v", err)
+		}
+	}
+
+	if err := writeArtifact("MAINTAINERS", file, 0755, config.Encodings["MAINTAINERS"]); err != nil {
+		logrus.Fatal(err)
+	}
+
+	if writeJSON {
+		file, err := encodeMaintainersJSON(projectMaintainers)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err := writeArtifact("MAINTAINERS.json", file, 0644, config.Encodings["MAINTAINERS.json"]); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	if writeEmailIndex {
+		file, err := encodeEmailIndex(projectMaintainers)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err := writeArtifact("MAINTAINERS.by-email.json", file, 0644, config.Encodings["MAINTAINERS.by-email.json"]); err != nil {
+			logrus.Fatal(err)
 		}
 	}
 
-	projectMaintainers.Org["Curators"].People = removeDuplicates(projectMaintainers.Org["Curators"].People)
-	projectMaintainers.Org["Docs maintainers"].People = removeDuplicates(projectMaintainers.Org["Docs maintainers"].People)
+	logrus.Infof("Successfully wrote new combined MAINTAINERS file.")
 
-	// encode the result to a file
+	if historyFile != "" {
+		if err := recordHistory(historyFile, projectMaintainers, c.Findings, c.Failures); err != nil {
+			logrus.Errorf("%s: recording history failed: %v", historyFile, err)
+		}
+	}
+
+	checkGates(config.Gates, projectMaintainers)
+}
+
+// encodeMaintainers returns the contents of the combined MAINTAINERS file,
+// in the layout given with -layout.
+func encodeMaintainers(projectMaintainers Maintainers) ([]byte, error) {
 	buf := new(bytes.Buffer)
 	t := toml.NewEncoder(buf)
 	t.Indent = "    "
-	if err := t.Encode(projectMaintainers); err != nil {
-		logrus.Fatalf("TOML encoding error: %v", err)
+	if err := t.Encode(inLayout(projectMaintainers, outputLayout)); err != nil {
+		return nil, fmt.Errorf("TOML encoding error: %v", err)
 	}
 
 	file := append([]byte(head), []byte(rules)...)
 	file = append(file, []byte(roles)...)
 	file = append(file, buf.Bytes()...)
+	return file, nil
+}
 
-	if err := ioutil.WriteFile("MAINTAINERS", file, 0755); err != nil {
-		logrus.Fatal(err)
+// encodeMaintainersJSON returns the combined maintainers, including the rules
+// and roles, as JSON. Like the TOML encoder, encoding/json sorts map keys, so
+// the output only changes when the data does.
+func encodeMaintainersJSON(projectMaintainers Maintainers) ([]byte, error) {
+	var r struct {
+		Rules map[string]Rule
+		Roles map[string]Role
+	}
+	if _, err := toml.Decode(rules+roles, &r); err != nil {
+		return nil, fmt.Errorf("parsing rules and roles failed: %v", err)
 	}
+	projectMaintainers.Rules, projectMaintainers.Roles = r.Rules, r.Roles
 
-	logrus.Infof("Successfully wrote new combined MAINTAINERS file.")
+	file, err := json.MarshalIndent(projectMaintainers, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("JSON encoding error: %v", err)
+	}
+	return append(file, '\n'), nil
+}
+
+// collectMaintainers runs the pipeline up to the enrich stage and returns
+// the combined maintainers. Projects whose file cannot be loaded are logged
+// and skipped.
+func collectMaintainers() Maintainers {
+	c, err := runPipeline("enrich")
+	if err != nil {
+		logrus.Error(err)
+	}
+	return c.Maintainers
 }
 
 func removeDuplicates(slice []string) []string {
@@ -167,6 +405,32 @@ func removeDuplicates(slice []string) []string {
 	return uniqs
 }
 
+// normalizeLadder lowercases the nicks of a project's contributor ladder and
+// drops promotion dates that are not formatted as YYYY-MM-DD.
+func normalizeLadder(project string, ladder map[string]Ladder) map[string]Ladder {
+	valid := func(nick, step, date string) string {
+		if date == "" {
+			return ""
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			logrus.Warnf("%s: invalid %s date %q for %s in Ladder", project, step, date, nick)
+			return ""
+		}
+		return date
+	}
+
+	l := make(map[string]Ladder, len(ladder))
+	for nick, steps := range ladder {
+		nick = strings.ToLower(nick)
+		l[nick] = Ladder{
+			Contributor: valid(nick, "contributor", steps.Contributor),
+			Reviewer:    valid(nick, "reviewer", steps.Reviewer),
+			Maintainer:  valid(nick, "maintainer", steps.Maintainer),
+		}
+	}
+	return l
+}
+
 // getProjectOrg splits a given project in GitHub organization and project/repository name.
 // If the given project does not have a GitHub organization, the default (`defaultOrg`) is used.
 func getProjectOrg(project string) (string, string) {
@@ -180,23 +444,18 @@ func getProjectOrg(project string) (string, string) {
 }
 
 func getMaintainers(org string, project string) (maintainers MaintainersDepreciated, err error) {
-	fileUrl := fmt.Sprintf("%s/%s/%s/master/MAINTAINERS", ghRawUri, org, project)
-
-	logrus.Infof("%s/%s: loading MAINTAINERS file from %v", org, project, fileUrl)
-
-	resp, err := http.Get(fileUrl)
+	file, err := getRawFile(org, project, "MAINTAINERS")
 	if err != nil {
-		return maintainers, fmt.Errorf("%s/%s: %v", org, project, err)
+		return maintainers, err
 	}
-	defer resp.Body.Close()
 
-	file, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return maintainers, fmt.Errorf("%s/%s: %v", org, project, err)
-	}
+	return parseMaintainers(org, project, file)
+}
 
+// parseMaintainers decodes the MAINTAINERS file of a project.
+func parseMaintainers(org string, project string, file []byte) (maintainers MaintainersDepreciated, err error) {
 	if _, err := toml.Decode(string(file), &maintainers); err != nil {
-		return maintainers, fmt.Errorf("%s/%s: parsing MAINTAINERS file failed: %v", org, project, err)
+		return maintainers, &fetchError{Class: classParse, Err: fmt.Errorf("%s/%s: parsing MAINTAINERS file failed: %v", org, project, err)}
 	}
 
 	return maintainers, nil
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// missingIssueTitle identifies the issue asking a repository to add a
+// MAINTAINERS file.
+const missingIssueTitle = "Add a MAINTAINERS file"
+
+// defaultMissingTemplate is the body of the issue opened by
+// missing-maintainers -open-issues, unless -template is given.
+var defaultMissingTemplate = `The maintainers of {{.Org}}/{{.Project}} are not listed anywhere: the repository has no MAINTAINERS file.
+
+The combined MAINTAINERS file of the {{.Org}} projects is generated from the MAINTAINERS file of each repository. Please add one at the root of the repository, for example:
+
+` + "```toml" + `
+[Org]
+	[Org."Core maintainers"]
+		people = [
+			"your-nick",
+		]
+
+[people]
+	[people.your-nick]
+	Name = "Your Name"
+	Email = "you@example.com"
+	GitHub = "your-github-handle"
+` + "```" + `
+
+This issue was opened by the maintainers collector.
+`
+
+// missingReport is the report of the missing-maintainers command.
+type missingReport struct {
+	Generated time.Time     `json:"generated"`
+	Orgs      []string      `json:"orgs"`
+	Repos     []missingRepo `json:"repos"`
+}
+
+// missingRepo is an active repository without a MAINTAINERS file.
+type missingRepo struct {
+	Org      string    `json:"org"`
+	Project  string    `json:"project"`
+	PushedAt time.Time `json:"pushed_at"`
+	Tracked  bool      `json:"tracked"`
+	Issue    string    `json:"issue,omitempty"`
+}
+
+// missingMaintainersCmd implements the missing-maintainers command.
+func missingMaintainersCmd(args []string) error {
+	fs := flag.NewFlagSet("missing-maintainers", flag.ExitOnError)
+	orgs := fs.String("orgs", "", "comma separated orgs to discover repositories in (default: the orgs of the tracked projects)")
+	active := fs.Duration("active", 90*24*time.Hour, "repositories pushed to within this duration are active")
+	output := fs.String("o", "", "also write the report as JSON to this file")
+	openIssues := fs.Bool("open-issues", false, "open an issue asking each repository to add a MAINTAINERS file, unless one was opened before")
+	templateFile := fs.String("template", "", "text/template file of the body of the issues (default: a built-in template)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: missing-maintainers [options]\n\n"+
+			"Discovers the active repositories of the orgs and reports those without a MAINTAINERS file.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	tmpl := template.New("issue")
+	if *templateFile != "" {
+		b, err := ioutil.ReadFile(*templateFile)
+		if err != nil {
+			return err
+		}
+		if _, err := tmpl.Parse(string(b)); err != nil {
+			return fmt.Errorf("%s: %v", *templateFile, err)
+		}
+	} else {
+		template.Must(tmpl.Parse(defaultMissingTemplate))
+	}
+
+	r := missingReport{Generated: time.Now().UTC(), Orgs: discoveryOrgs(*orgs)}
+	tracked := map[string]bool{}
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		tracked[strings.ToLower(org+"/"+project)] = true
+	}
+
+	since := time.Now().Add(-*active)
+	for _, org := range r.Orgs {
+		repos, err := activeRepos(org, since)
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			if _, err := getRawFile(org, repo.Name, "MAINTAINERS"); err == nil {
+				continue
+			} else if errorClass(err) != classMissing {
+				logrus.Warnf("%s/%s: %v", org, repo.Name, err)
+				continue
+			}
+
+			m := missingRepo{Org: org, Project: repo.Name, PushedAt: repo.PushedAt, Tracked: tracked[strings.ToLower(org+"/"+repo.Name)]}
+			if *openIssues {
+				url, err := openMissingIssue(org, repo.Name, tmpl)
+				if err != nil {
+					logrus.Error(err)
+				}
+				m.Issue = url
+			}
+			r.Repos = append(r.Repos, m)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tLAST PUSH\tTRACKED\tISSUE")
+	for _, m := range r.Repos {
+		issue := m.Issue
+		if issue == "" {
+			issue = "-"
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%t\t%s\n", m.Org, m.Project, m.PushedAt.Format("2006-01-02"), m.Tracked, issue)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if *output != "" {
+		b, err := json.MarshalIndent(r, "", "    ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(*output, append(b, '\n'), 0644)
+	}
+	return nil
+}
+
+// discoveryOrgs returns the orgs of the comma separated list, or the orgs of
+// the tracked projects if it is empty.
+func discoveryOrgs(list string) []string {
+	seen := map[string]bool{}
+	var orgs []string
+	add := func(org string) {
+		if org != "" && !seen[strings.ToLower(org)] {
+			seen[strings.ToLower(org)] = true
+			orgs = append(orgs, org)
+		}
+	}
+	if list != "" {
+		for _, org := range strings.Split(list, ",") {
+			add(strings.TrimSpace(org))
+		}
+		return orgs
+	}
+	for _, p := range projects {
+		org, _ := getProjectOrg(p)
+		add(org)
+	}
+	sort.Strings(orgs)
+	return orgs
+}
+
+// discoveredRepo is a repository of an org, as listed by GitHub.
+type discoveredRepo struct {
+	Name     string    `json:"name"`
+	Archived bool      `json:"archived"`
+	Disabled bool      `json:"disabled"`
+	Fork     bool      `json:"fork"`
+	PushedAt time.Time `json:"pushed_at"`
+}
+
+// activeRepos lists the repositories of org pushed to since the given time,
+// leaving out forks and archived repositories.
+func activeRepos(org string, since time.Time) ([]discoveredRepo, error) {
+	var active []discoveredRepo
+	for page := 1; ; page++ {
+		var repos []discoveredRepo
+		if err := githubGet(fmt.Sprintf("/orgs/%s/repos?type=sources&per_page=100&page=%d", org, page), &repos); err != nil {
+			return nil, fmt.Errorf("%s: listing repositories failed: %v", org, err)
+		}
+		for _, r := range repos {
+			if !r.Archived && !r.Disabled && !r.Fork && r.PushedAt.After(since) {
+				active = append(active, r)
+			}
+		}
+		if len(repos) < 100 {
+			break
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Name < active[j].Name })
+	return active, nil
+}
+
+// openMissingIssue opens the issue asking org/project to add a MAINTAINERS
+// file, with the body rendered from tmpl, and returns its URL. If the issue
+// was opened before, open or closed, it is left alone.
+func openMissingIssue(org, project string, tmpl *template.Template) (string, error) {
+	var issues []struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/issues?state=all&per_page=100", org, project), &issues); err != nil {
+		return "", fmt.Errorf("%s/%s: %v", org, project, err)
+	}
+	for _, i := range issues {
+		if i.Title == missingIssueTitle {
+			return i.HTMLURL, nil
+		}
+	}
+
+	body := new(bytes.Buffer)
+	if err := tmpl.Execute(body, struct{ Org, Project string }{org, project}); err != nil {
+		return "", fmt.Errorf("%s/%s: rendering the issue failed: %v", org, project, err)
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/issues", org, project), map[string]string{
+		"title": missingIssueTitle,
+		"body":  body.String(),
+	}, &created); err != nil {
+		return "", fmt.Errorf("%s/%s: opening issue failed: %v", org, project, err)
+	}
+	logrus.Infof("opened issue %s", created.HTMLURL)
+	return created.HTMLURL, nil
+}
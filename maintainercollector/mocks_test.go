@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/docker/opensource/maintainercollector/clients/clientstest"
+)
+
+// serveFiles returns a fetcher mock serving files from memory, keyed by
+// "org/project/path".
+func serveFiles(files map[string][]byte) *clientstest.Fetcher {
+	return &clientstest.Fetcher{FetchFunc: func(org string, project string, path string) ([]byte, error) {
+		b, ok := files[org+"/"+project+"/"+path]
+		if !ok {
+			return nil, &fetchError{Class: classMissing, Err: fmt.Errorf("%s/%s: fetching %s failed: %s", org, project, path, http.StatusText(http.StatusNotFound))}
+		}
+		return b, nil
+	}}
+}
+
+// answerGitHub returns a GitHub API mock answering from memory. Responses
+// are keyed by "METHOD path" and are JSON encoded and decoded to fill the
+// result, like a real response, except errors which are returned as is.
+// Requests without response fail with 404 Not Found.
+func answerGitHub(responses map[string]interface{}) *clientstest.GitHub {
+	return &clientstest.GitHub{RequestFunc: func(method string, path string, body interface{}, v interface{}) error {
+		resp, ok := responses[method+" "+path]
+		if !ok {
+			return &githubError{Method: method, Path: path, StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+		}
+		if err, ok := resp.(error); ok {
+			return err
+		}
+		if v == nil {
+			return nil
+		}
+
+		b, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}}
+}
+
+// failNotifications returns a notifier mock failing every notification with
+// err, after recording it.
+func failNotifications(err error) *clientstest.Notifier {
+	return &clientstest.Notifier{NotifyFunc: func(url string, secret string, payload []byte) error {
+		return err
+	}}
+}
+
+// memoryStore returns a store mock keeping the files in files, keyed by
+// path.
+func memoryStore(files map[string][]byte) *clientstest.Store {
+	var mu sync.Mutex
+	return &clientstest.Store{
+		LoadFunc: func(path string) ([]byte, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			b, ok := files[path]
+			if !ok {
+				return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+			}
+			return b, nil
+		},
+		SaveFunc: func(path string, data []byte, perm os.FileMode) error {
+			mu.Lock()
+			defer mu.Unlock()
+			files[path] = append([]byte(nil), data...)
+			return nil
+		},
+	}
+}
+
+// useMocks replaces the GitHub API client, the fetcher, the notifier and the
+// store by the given mocks for the duration of the test. Nil mocks leave the
+// real implementations in place.
+func useMocks(t *testing.T, gh *clientstest.GitHub, f fetcher, n *clientstest.Notifier, st *clientstest.Store) {
+	savedGitHub, savedFetcher, savedNotifier, savedStore := github, rawFiles, notifications, files
+	if gh != nil {
+		github = gh
+	}
+	if f != nil {
+		rawFiles = f
+	}
+	if n != nil {
+		notifications = n
+	}
+	if st != nil {
+		files = st
+	}
+	t.Cleanup(func() { github, rawFiles, notifications, files = savedGitHub, savedFetcher, savedNotifier, savedStore })
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// repoFile is a file in a repository, as returned by the GitHub contents API.
+// Content is normalized, see normalizeText, and Encoding is the encoding of
+// the file in the repository, restored when updating it.
+type repoFile struct {
+	Path     string
+	Sha      string
+	Branch   string
+	Content  []byte
+	Encoding Encoding
+}
+
+// nominateCmd implements the nominate command.
+func nominateCmd(args []string) error {
+	fs := flag.NewFlagSet("nominate", flag.ExitOnError)
+	name := fs.String("name", "", "full name of the nominee (default: from their GitHub profile)")
+	email := fs.String("email", "", "email address of the nominee (default: from their GitHub profile)")
+	dryRun := fs.Bool("dry-run", false, "print the updated MAINTAINERS file instead of opening a pull request")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: nominate [options] <project> <handle>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("nominate: expected a project and a GitHub handle")
+	}
+	org, project := getProjectOrg(fs.Arg(0))
+	handle := fs.Arg(1)
+
+	person, err := lookupNominee(handle, *name, *email)
+	if err != nil {
+		return fmt.Errorf("nominate: %v, use -name and -email", err)
+	}
+
+	file, err := getRepoFile(org, project, "MAINTAINERS")
+	if err != nil {
+		return err
+	}
+
+	var current MaintainersDepreciated
+	if _, err := toml.Decode(string(file.Content), &current); err != nil {
+		return fmt.Errorf("%s/%s: parsing MAINTAINERS file failed: %v", org, project, err)
+	}
+	section, people := maintainersSection(current)
+	if section == "" {
+		return fmt.Errorf("%s/%s: MAINTAINERS file has no maintainers section", org, project)
+	}
+	for _, p := range people {
+		if strings.EqualFold(p, handle) {
+			return fmt.Errorf("%s/%s: %s is already a maintainer", org, project, handle)
+		}
+	}
+
+	updated, err := addMaintainer(string(file.Content), section, strings.ToLower(handle), person)
+	if err != nil {
+		return fmt.Errorf("%s/%s: %v", org, project, err)
+	}
+
+	approvals := requiredApprovals(project, people)
+	if *dryRun {
+		fmt.Print(updated)
+		logrus.Infof("%s/%s: nomination requires %s", org, project, approvals)
+		return nil
+	}
+
+	title := fmt.Sprintf("Add %s as a maintainer", handle)
+	body := fmt.Sprintf("This nominates @%s (%s) as a maintainer of %s/%s.\n\n"+
+		"Per the project governance (see \"How are maintainers added?\"), this change "+
+		"requires %s.\n\nMaintainers, please vote by approving or requesting changes on this pull request.\n",
+		person.GitHub, person.Name, org, project, approvals)
+
+	pr, err := openPullRequest(org, project, file, uniqueBranch("nominate-"+strings.ToLower(handle)), title, body, updated)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(pr)
+	logrus.Infof("%s/%s: opened %s, it requires %s", org, project, pr, approvals)
+	return nil
+}
+
+// lookupNominee returns the People entry of the GitHub user handle, with the
+// given name and email, or else the ones of their GitHub profile.
+func lookupNominee(handle, name, email string) (Person, error) {
+	person := Person{Name: name, Email: email, GitHub: handle}
+	if person.Name == "" || person.Email == "" {
+		var user struct {
+			Login string `json:"login"`
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+		if err := githubGet("/users/"+handle, &user); err != nil {
+			return person, fmt.Errorf("looking up %s: %v", handle, err)
+		}
+		person.GitHub = user.Login
+		if person.Name == "" {
+			person.Name = user.Name
+		}
+		if person.Email == "" {
+			person.Email = user.Email
+		}
+	}
+	if person.Name == "" || person.Email == "" {
+		return person, fmt.Errorf("%s has no public name or email", handle)
+	}
+	return person, nil
+}
+
+// maintainersSection returns the name of the Org section listing a project's
+// maintainers and its members, mirroring the lookup done when collecting.
+func maintainersSection(m MaintainersDepreciated) (string, []string) {
+	if m.Organization.Maintainers != nil {
+		return "Maintainers", m.Organization.Maintainers.People
+	}
+	if m.Organization.CoreMaintainers != nil {
+		return "Core maintainers", m.Organization.CoreMaintainers.People
+	}
+	return "", nil
+}
+
+// addMaintainer adds nick to the People list of the given Org section and a
+// People entry for person to the contents of a MAINTAINERS file. The rest of
+// the file is left untouched, so the result keeps the project's formatting.
+func addMaintainer(file string, section string, nick string, person Person) (string, error) {
+	file, err := editSectionPeople(file, section, func(nicks []string) []string {
+		nicks = append(nicks, nick)
+		sort.Strings(nicks)
+		return nicks
+	})
+	if err != nil {
+		return "", err
+	}
+	return addPerson(file, nick, person), nil
+}
+
+// editSectionPeople replaces the People list of the given Org section in the
+// contents of a MAINTAINERS file with the result of edit.
+func editSectionPeople(file string, section string, edit func([]string) []string) (string, error) {
+	header := regexp.MustCompile(`(?mi)^[ \t]*\[org\.(` + regexp.QuoteMeta(section) + `|"` + regexp.QuoteMeta(section) + `")\][ \t]*$`)
+	loc := header.FindStringIndex(file)
+	if loc == nil {
+		return "", fmt.Errorf("cannot find the [Org.%q] section", section)
+	}
+
+	list := regexp.MustCompile(`(?i)people\s*=\s*\[([^\]]*)\]`)
+	m := list.FindStringSubmatchIndex(file[loc[1]:])
+	if m == nil {
+		return "", fmt.Errorf("cannot find the people of the [Org.%q] section", section)
+	}
+	start, end := loc[1]+m[2], loc[1]+m[3]
+	items := file[start:end]
+
+	var nicks []string
+	for _, n := range regexp.MustCompile(`"[^"]*"`).FindAllString(items, -1) {
+		nicks = append(nicks, strings.Trim(n, `"`))
+	}
+	nicks = edit(nicks)
+
+	quoted := make([]string, len(nicks))
+	for i, n := range nicks {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+
+	// keep multi-line lists multi-line, using the indentation of the first item
+	var replacement string
+	if strings.Contains(items, "\n") && len(quoted) > 0 {
+		indent := regexp.MustCompile(`\n([ \t]*)"`).FindStringSubmatch(items)
+		prefix := "\t\t\t"
+		if indent != nil {
+			prefix = indent[1]
+		}
+		tail := strings.TrimLeft(items[strings.LastIndex(items, `"`)+1:], ",")
+		replacement = "\n" + prefix + strings.Join(quoted, ",\n"+prefix)
+		if strings.Contains(tail, "\n") {
+			replacement += ","
+		}
+		replacement += tail
+	} else {
+		replacement = strings.Join(quoted, ", ")
+	}
+
+	return file[:start] + replacement + file[end:], nil
+}
+
+// addPerson inserts a People entry for person in alphabetical order,
+// following the indentation and casing used by the existing entries.
+func addPerson(file string, nick string, person Person) string {
+	headers := regexp.MustCompile(`(?mi)^([ \t]*)\[(people)\.("?)([^\]"]+)"?\][ \t]*$`)
+	matches := headers.FindAllStringSubmatchIndex(file, -1)
+
+	indent, keyIndent, prefix, insert := "\t", "\t\t", "people", len(file)
+	for _, m := range matches {
+		indent, prefix = file[m[2]:m[3]], file[m[4]:m[5]]
+		if next := regexp.MustCompile(`^\n([ \t]*)\S`).FindStringSubmatch(file[m[1]:]); next != nil {
+			keyIndent = next[1]
+		}
+		if strings.ToLower(file[m[8]:m[9]]) > nick {
+			insert = m[0]
+			break
+		}
+	}
+
+	key := nick
+	if strings.ContainsAny(key, ".") {
+		key = fmt.Sprintf("%q", key)
+	}
+	entry := fmt.Sprintf("%[1]s[%[2]s.%[3]s]\n%[4]sName = %[5]q\n%[4]sEmail = %[6]q\n%[4]sGitHub = %[7]q\n\n",
+		indent, prefix, key, keyIndent, person.Name, person.Email, person.GitHub)
+
+	if insert == len(file) {
+		return strings.TrimRight(file, "\n") + "\n\n" + strings.TrimRight(entry, "\n") + "\n"
+	}
+	return file[:insert] + entry + file[insert:]
+}
+
+// getRepoFile fetches a file from the default branch of a repository.
+func getRepoFile(org, project, path string) (*repoFile, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s", org, project), &repo); err != nil {
+		return nil, fmt.Errorf("%s/%s: %v", org, project, err)
+	}
+	return getBranchFile(org, project, path, repo.DefaultBranch)
+}
+
+// getBranchFile fetches a file from a branch of a repository.
+func getBranchFile(org, project, path, branch string) (*repoFile, error) {
+	var content struct {
+		Sha     string `json:"sha"`
+		Content string `json:"content"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", org, project, path, url.QueryEscape(branch)), &content); err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", org, project, err)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.Replace(content.Content, "\n", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: decoding %s failed: %v", org, project, path, err)
+	}
+
+	return &repoFile{Path: path, Sha: content.Sha, Branch: branch, Content: normalizeText(b), Encoding: detectEncoding(b)}, nil
+}
+
+// uniqueBranch returns a branch name starting with prefix and unique to the
+// run, so that proposals of later runs do not collide with branches left by
+// earlier ones.
+func uniqueBranch(prefix string) string {
+	return prefix + "-" + time.Now().UTC().Format("20060102-150405")
+}
+
+// openPullRequest commits content as the new version of file on a new
+// branch, in the encoding of file, and opens a pull request for it against
+// the default branch. It returns the URL of the pull request.
+func openPullRequest(org, project string, file *repoFile, branch, title, body, content string) (string, error) {
+	var ref struct {
+		Object struct {
+			Sha string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", org, project, file.Branch), &ref); err != nil {
+		return "", fmt.Errorf("%s/%s: %v", org, project, err)
+	}
+
+	if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/git/refs", org, project), map[string]string{
+		"ref": "refs/heads/" + branch,
+		"sha": ref.Object.Sha,
+	}, nil); err != nil {
+		return "", fmt.Errorf("%s/%s: creating branch %s failed: %v", org, project, branch, err)
+	}
+
+	if err := githubRequest("PUT", fmt.Sprintf("/repos/%s/%s/contents/%s", org, project, file.Path), map[string]string{
+		"message": title,
+		"content": base64.StdEncoding.EncodeToString(file.Encoding.apply([]byte(content))),
+		"sha":     file.Sha,
+		"branch":  branch,
+	}, nil); err != nil {
+		return "", fmt.Errorf("%s/%s: updating %s failed: %v", org, project, file.Path, err)
+	}
+
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/pulls", org, project), map[string]string{
+		"title": title,
+		"head":  branch,
+		"base":  file.Branch,
+		"body":  body,
+	}, &pr); err != nil {
+		return "", fmt.Errorf("%s/%s: opening pull request failed: %v", org, project, err)
+	}
+
+	return pr.HTMLURL, nil
+}
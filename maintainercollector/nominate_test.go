@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestOpenPullRequest(t *testing.T) {
+	gh := answerGitHub(map[string]interface{}{
+		"GET /repos/docker/cli/git/ref/heads/master": map[string]interface{}{"object": map[string]string{"sha": "abc"}},
+		"POST /repos/docker/cli/git/refs":            map[string]string{},
+		"PUT /repos/docker/cli/contents/MAINTAINERS": map[string]string{},
+		"POST /repos/docker/cli/pulls":               map[string]string{"html_url": "https://github.com/docker/cli/pull/1"},
+	})
+	useMocks(t, gh, nil, nil, nil)
+
+	file := &repoFile{Path: "MAINTAINERS", Sha: "def", Branch: "master", Encoding: Encoding{LineEndings: "crlf"}}
+	pr, err := openPullRequest("docker", "cli", file, "nominate-bob", "Add bob as a maintainer", "", "[Org]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr != "https://github.com/docker/cli/pull/1" {
+		t.Errorf("got pull request %q", pr)
+	}
+
+	if len(gh.RequestCalls) != 4 {
+		t.Fatalf("got %d requests, want 4: %+v", len(gh.RequestCalls), gh.RequestCalls)
+	}
+	put := gh.RequestCalls[2].Body.(map[string]string)
+	if put["branch"] != "nominate-bob" || put["sha"] != "def" {
+		t.Errorf("got update %+v, want the blob def on branch nominate-bob", put)
+	}
+	if content, _ := base64.StdEncoding.DecodeString(put["content"]); string(content) != "[Org]\r\n" {
+		t.Errorf("got content %q, want the CRLF line endings of the file", content)
+	}
+}
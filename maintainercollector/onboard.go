@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+)
+
+// onboardingRules are the rules (see rules.toml) excerpted in onboarding
+// packets, in order.
+var onboardingRules = []string{"maintainers", "review", "no direct push", "DCO", "stepping-down-policy"}
+
+var onboardingTemplate = template.Must(template.New("onboarding").Parse(`# Welcome, {{.Person.Name}}!
+
+You are now listed as a maintainer (@{{.Person.GitHub}}, {{.Person.Email}}) of the
+following Docker projects. Thank you for taking on this role.
+{{range .Projects}}
+## {{.Org}}/{{.Name}}
+
+Repository: https://github.com/{{.Org}}/{{.Name}}
+{{if .Fellows}}
+Fellow maintainers:
+{{range .Fellows}}
+- {{.Name}} (@{{.GitHub}})
+{{- end}}
+{{else}}
+You are the only maintainer of this project.
+{{end}}
+Teams to be invited to:
+{{if .Teams}}{{range .Teams}}
+- {{.}}
+{{- end}}{{else}}
+- ask a fellow maintainer which GitHub teams give access to {{.Org}}/{{.Name}}
+{{- end}}
+{{end}}
+# Governance
+{{range .Rules}}
+## {{.Title}}
+
+{{.Text}}
+{{- end}}
+`))
+
+// onboardingProject is a project in an onboarding packet.
+type onboardingProject struct {
+	Org     string
+	Name    string
+	Fellows []Person
+	Teams   []string
+}
+
+// onboardCmd implements the onboard command.
+func onboardCmd(args []string) error {
+	fs := flag.NewFlagSet("onboard", flag.ExitOnError)
+	output := fs.String("o", "", "write the packet to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: onboard [options] <nick>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("onboard: expected the nick of a maintainer")
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return writeOnboarding(w, collectMaintainers(), strings.ToLower(fs.Arg(0)))
+}
+
+// writeOnboarding writes the onboarding packet of a maintainer as Markdown.
+func writeOnboarding(w io.Writer, m Maintainers, nick string) error {
+	person, ok := m.People[nick]
+	if !ok {
+		return fmt.Errorf("onboard: %s has no People entry", nick)
+	}
+	if person.GitHub == "" {
+		person.GitHub = nick
+	}
+
+	orgs := map[string]string{}
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		orgs[sectionName(project)] = org
+	}
+
+	var packet []onboardingProject
+	for _, name := range m.Projects() {
+		if !containsFold(m.Org[name].People, nick) {
+			continue
+		}
+		p := onboardingProject{Org: orgs[name], Name: name, Teams: config.Teams[name]}
+		if p.Org == "" {
+			p.Org = defaultOrg
+		}
+		for _, fellow := range m.Org[name].People {
+			if fellow == nick {
+				continue
+			}
+			f, ok := m.People[fellow]
+			if !ok {
+				f = Person{Name: fellow}
+			}
+			if f.GitHub == "" {
+				f.GitHub = fellow
+			}
+			p.Fellows = append(p.Fellows, f)
+		}
+		packet = append(packet, p)
+	}
+	if len(packet) == 0 {
+		return fmt.Errorf("onboard: %s is not a maintainer of any project", nick)
+	}
+
+	var r struct {
+		Rules map[string]Rule
+	}
+	if _, err := toml.Decode(rules, &r); err != nil {
+		return fmt.Errorf("parsing rules failed: %v", err)
+	}
+	var excerpt []Rule
+	for _, name := range onboardingRules {
+		if rule, ok := r.Rules[name]; ok {
+			excerpt = append(excerpt, rule)
+		}
+	}
+
+	sort.Slice(packet, func(i, j int) bool { return packet[i].Name < packet[j].Name })
+	return onboardingTemplate.Execute(w, struct {
+		Person   Person
+		Projects []onboardingProject
+		Rules    []Rule
+	}{person, packet, excerpt})
+}
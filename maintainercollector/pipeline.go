@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// The combined MAINTAINERS file is produced by a pipeline of stages, each
+// reading and completing a collection:
+//
+//	fetch      loads the MAINTAINERS file of every project, in parallel, or
+//	           runs its source, see sources.go
+//	parse      extracts the maintainers of each project from its file,
+//	           leaving out people outside their term, see terms.go
+//	normalize  lowercases and sorts nicks, validates ladder dates
+//	merge      combines the projects into a single Maintainers, grouping
+//	           aliased projects
+//	enrich     adds the committees and working groups, and completes people
+//	           from their GitHub profile, see enrich.go
+//	audit      lists the governance findings
+//	encode     encodes the combined MAINTAINERS file
+//	export     runs the exporters of the configuration, see exporters.go
+//	postgres   loads the maintainers into the Postgres sink, see postgres.go
+//
+// More stages are added with registerStage.
+
+// collection is the data passed along the stages of the pipeline.
+type collection struct {
+	// Projects are the projects to collect, as "org/project" or "project",
+	// including those of the sources of the configuration.
+	Projects []string
+
+	// Sources are the projects whose MAINTAINERS file was loaded, in the
+	// order of Projects.
+	Sources []*projectSource
+
+	// Failures are the projects whose MAINTAINERS file failed to load, in
+	// the order of Projects, including those replaced by a stale copy.
+	Failures []projectFailure
+
+	Maintainers Maintainers
+	Findings    []finding
+	File        []byte
+}
+
+// projectFailure is a failure to load the MAINTAINERS file of a project.
+type projectFailure struct {
+	Org     string
+	Project string
+	Err     error
+
+	// Section is the name of the Org section of the project.
+	Section string
+
+	// Stale is set if the last-known-good copy of the file was used.
+	Stale bool
+}
+
+// projectSource is the data of a single project.
+type projectSource struct {
+	Org     string
+	Project string
+
+	// File is the MAINTAINERS file of the project, as loaded by fetch.
+	File MaintainersDepreciated
+
+	// The maintainers of the project, as extracted by parse.
+	Maintainers []string
+	Docs        []string
+	Curators    []string
+	People      map[string]Person
+	Ladder      map[string]Ladder
+	Components  map[string]*Component
+}
+
+// stage is a step of the pipeline.
+type stage struct {
+	Name string
+	Run  func(c *collection) error
+}
+
+// stages are the stages of the pipeline, in order.
+var stages = []stage{
+	{"fetch", fetchStage},
+	{"parse", parseStage},
+	{"normalize", normalizeStage},
+	{"merge", mergeStage},
+	{"enrich", enrichStage},
+	{"audit", auditStage},
+	{"encode", encodeStage},
+}
+
+// fetchConcurrency is the number of projects fetched at the same time.
+var fetchConcurrency = 8
+
+// registerStage adds s to the pipeline after the stage named after.
+func registerStage(s stage, after string) error {
+	for i, t := range stages {
+		if t.Name == after {
+			stages = append(stages[:i+1], append([]stage{s}, stages[i+1:]...)...)
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot register stage %s: no stage %s", s.Name, after)
+}
+
+// runPipeline runs the stages up to and including the stage named until,
+// or all of them if until is empty, on the current projects. The run report
+// is reset first.
+func runPipeline(until string) (*collection, error) {
+	report.reset()
+	c := &collection{Projects: collectedProjects()}
+	if err := duplicateProjects(c.Projects, config.MergeDuplicates); err != nil {
+		return c, err
+	}
+	for _, s := range stages {
+		if err := s.Run(c); err != nil {
+			return c, fmt.Errorf("%s: %v", s.Name, err)
+		}
+		if s.Name == until {
+			break
+		}
+	}
+	return c, nil
+}
+
+// fetchStage loads the MAINTAINERS file of every project. Projects whose
+// file cannot be loaded are logged and skipped.
+//
+// Only fetching is concurrent: each goroutine writes its own slot of
+// sources and failures, and shared state it updates (runState, report) is
+// locked. The later stages, merge included, run on a single goroutine, so
+// the combined Maintainers has a single writer and needs no locking.
+func fetchStage(c *collection) error {
+	sources := make([]*projectSource, len(c.Projects))
+	failures := make([]*projectFailure, len(c.Projects))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, fetchConcurrency)
+	for i, p := range c.Projects {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			org, project := getProjectOrg(p)
+			var m MaintainersDepreciated
+			var stale, err error
+			if src, ok := projectSourcePlugin(org, project); ok {
+				m, err = loadFromSource(org, project, src)
+			} else {
+				m, stale, err = loadMaintainers(org, project)
+			}
+			if err != nil {
+				logrus.Errorf("%s: parsing MAINTAINERS file failed: %v", project, err)
+				failures[i] = &projectFailure{Org: org, Project: project, Err: err, Section: sectionName(project)}
+				return
+			}
+			if stale != nil {
+				failures[i] = &projectFailure{Org: org, Project: project, Err: stale, Section: sectionName(project), Stale: true}
+			}
+			sources[i] = &projectSource{Org: org, Project: project, File: m}
+		}(i, p)
+	}
+	wg.Wait()
+
+	for i, s := range sources {
+		if s != nil {
+			c.Sources = append(c.Sources, s)
+		}
+		if f := failures[i]; f != nil {
+			c.Failures = append(c.Failures, *f)
+		}
+	}
+	return nil
+}
+
+// parseStage extracts the maintainers of each project from its file.
+func parseStage(c *collection) error {
+	now := time.Now()
+	for _, s := range c.Sources {
+		o := s.File.Organization
+		if o.Maintainers != nil {
+			s.Maintainers = activeMembers(s.Project, "Maintainers", o.Maintainers, now)
+		} else if o.CoreMaintainers != nil {
+			// TODO: change this to use the "Core maintainers" Org section
+			// once MaintainersDepreciated is removed.
+			s.Maintainers = activeMembers(s.Project, "Core maintainers", o.CoreMaintainers, now)
+		}
+		if o.DocsMaintainers != nil {
+			s.Docs = activeMembers(s.Project, "Docs maintainers", o.DocsMaintainers, now)
+		}
+		if o.Curators != nil {
+			s.Curators = activeMembers(s.Project, "Curators", o.Curators, now)
+		}
+		s.People = s.File.People
+		s.Ladder = s.File.Ladder
+		s.Components = s.File.Components
+	}
+	return nil
+}
+
+// normalizeStage lowercases all nicks for consistency, sorts the
+// maintainers and drops invalid ladder dates and component paths.
+func normalizeStage(c *collection) error {
+	for _, s := range c.Sources {
+		maintainers := make([]string, len(s.Maintainers))
+		for i, n := range s.Maintainers {
+			maintainers[i] = strings.ToLower(n)
+		}
+		sort.Strings(maintainers)
+		s.Maintainers = maintainers
+
+		people := make(map[string]Person, len(s.People))
+		for nick, person := range s.People {
+			people[strings.ToLower(nick)] = person
+		}
+		s.People = people
+
+		if len(s.Ladder) > 0 {
+			s.Ladder = normalizeLadder(s.Project, s.Ladder)
+		}
+		if len(s.Components) > 0 {
+			s.Components = normalizeComponents(s.Project, s.Components)
+		}
+	}
+	return nil
+}
+
+// mergeStage combines the projects into a single Maintainers.
+func mergeStage(c *collection) error {
+	m := Maintainers{
+		Org:    map[string]*Org{},
+		People: map[string]Person{},
+	}
+
+	// initialize Curators
+	m.Org["Curators"] = &Org{}
+	m.Org["Docs maintainers"] = &Org{}
+
+	for _, s := range c.Sources {
+		section := sectionName(s.Project)
+		if o, ok := m.Org[section]; ok {
+			// grouped with another project by an alias
+			o.People = removeDuplicates(append(o.People, s.Maintainers...))
+		} else {
+			m.Org[section] = &Org{People: s.Maintainers}
+		}
+		m.Org["Docs maintainers"].People = append(m.Org["Docs maintainers"].People, s.Docs...)
+		m.Org["Curators"].People = append(m.Org["Curators"].People, s.Curators...)
+
+		for nick, person := range s.People {
+			m.People[nick] = person
+		}
+
+		if len(s.Ladder) > 0 {
+			if m.Ladder == nil {
+				m.Ladder = map[string]map[string]Ladder{}
+			}
+			if m.Ladder[section] == nil {
+				m.Ladder[section] = map[string]Ladder{}
+			}
+			for nick, l := range s.Ladder {
+				m.Ladder[section][nick] = l
+			}
+		}
+
+		if len(s.Components) > 0 {
+			if m.Components == nil {
+				m.Components = map[string]map[string]*Component{}
+			}
+			if m.Components[section] == nil {
+				m.Components[section] = map[string]*Component{}
+			}
+			for name, comp := range s.Components {
+				m.Components[section][name] = comp
+			}
+		}
+	}
+
+	m.Org["Curators"].People = removeDuplicates(m.Org["Curators"].People)
+	m.Org["Docs maintainers"].People = removeDuplicates(m.Org["Docs maintainers"].People)
+
+	for name, o := range m.Org {
+		o.Title = config.DisplayNames[name]
+	}
+
+	c.Maintainers = m
+	return nil
+}
+
+// sectionName returns the name of the Org section of a project, following
+// the aliases of the configuration.
+func sectionName(project string) string {
+	if alias, ok := config.Aliases[project]; ok {
+		return alias
+	}
+	return project
+}
+
+// enrichStage adds the committees and working groups, with -fallback-people
+// synthesizes missing People entries, and with -enrich-profiles completes
+// people from the identity providers. Failures are logged, the combined file
+// is still generated without them.
+func enrichStage(c *collection) error {
+	if err := addGroups(&c.Maintainers); err != nil {
+		logrus.Errorf("loading committees and working groups failed: %v", err)
+	}
+	if fallbackPeople {
+		addFallbackPeople(c)
+	}
+	if enrichProfiles {
+		enrichPeople(&c.Maintainers)
+	}
+	return nil
+}
+
+// auditStage lists the governance findings.
+func auditStage(c *collection) error {
+	c.Findings = append(governanceFindings(c.Maintainers, c.Failures), validateProjects(c)...)
+	c.Findings = append(c.Findings, termFindings(c, time.Now())...)
+	sortFindings(c.Findings)
+	return nil
+}
+
+// encodeStage encodes the combined MAINTAINERS file.
+func encodeStage(c *collection) error {
+	file, err := encodeMaintainers(c.Maintainers)
+	if err != nil {
+		return err
+	}
+	c.File = file
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the output of the tests")
+
+// withProjects runs the test with the given projects and configuration,
+// restoring the global ones afterwards.
+func withProjects(t *testing.T, list []string, c Config) {
+	savedProjects, savedConfig := projects, config
+	projects, config = list, c
+	t.Cleanup(func() { projects, config = savedProjects, savedConfig })
+}
+
+// checkGolden compares got with the golden file name, or rewrites it with
+// -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file, run go test -update to see the changes:\n%s", name, got)
+	}
+}
+
+// TestPipelineFixtures runs the whole pipeline against the recorded
+// fixtures, without network access: a project listed under another org, a
+// project aliased to another one and a project without MAINTAINERS file.
+func TestPipelineFixtures(t *testing.T) {
+	stop := useFakeGitHub(filepath.Join("testdata", "fixtures"))
+	defer stop()
+	withProjects(t, []string{"cli", "compose", "migrator", "v1.10-migrator", "moby/moby", "missing"},
+		Config{Aliases: map[string]string{"v1.10-migrator": "migrator"}})
+
+	c, err := runPipeline("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "MAINTAINERS", c.File)
+
+	file, err := encodeMaintainersJSON(c.Maintainers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "MAINTAINERS.json", file)
+}
+
+// TestPipelineReport runs the pipeline twice, as serve does, and checks that
+// the report and the findings only hold the projects of the last run.
+func TestPipelineReport(t *testing.T) {
+	stop := useFakeGitHub(filepath.Join("testdata", "fixtures"))
+	defer stop()
+	withProjects(t, []string{"cli", "missing"}, Config{})
+
+	var c *collection
+	for i := 0; i < 2; i++ {
+		var err error
+		if c, err = runPipeline(""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var failed []finding
+	for _, f := range c.Findings {
+		if f.Kind == findingFailed {
+			failed = append(failed, f)
+		}
+	}
+	if len(failed) != 1 || failed[0].Project != "missing" {
+		t.Errorf("got failure findings %+v, want the one of missing", failed)
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	if len(report.Projects) != 2 || report.Failures[classMissing] != 1 {
+		t.Errorf("got %d projects and failures %v in the report, want 2 projects and 1 missing", len(report.Projects), report.Failures)
+	}
+	if report.total[classMissing] < 2 {
+		t.Errorf("got total failures %v, want the failures of both runs", report.total)
+	}
+}
+
+// fakeProjects returns n projects, each maintained by alice and a
+// maintainer of its own, and a fetcher serving their MAINTAINERS files.
+func fakeProjects(n int) ([]string, map[string][]byte) {
+	var list []string
+	f := map[string][]byte{}
+	for i := 0; i < n; i++ {
+		project := fmt.Sprintf("project%d", i)
+		list = append(list, "docker/"+project)
+		f["docker/"+project+"/MAINTAINERS"] = []byte(fmt.Sprintf(`[Org]
+	[Org."Core maintainers"]
+		people = ["alice", "user%[1]d"]
+
+[people]
+	[people.alice]
+	Name = "Alice"
+	Email = "alice@example.com"
+	GitHub = "alice"
+
+	[people.user%[1]d]
+	Name = "User %[1]d"
+	Email = "user%[1]d@example.com"
+	GitHub = "user%[1]d"
+`, i))
+	}
+	return list, f
+}
+
+// TestFetchStageConcurrent fetches projects concurrently, and checks that
+// the sources and failures keep the order of the projects. Run it with
+// -race.
+func TestFetchStageConcurrent(t *testing.T) {
+	list, f := fakeProjects(40)
+	list = append(list, "docker/missing")
+	useMocks(t, answerGitHub(nil), serveFiles(f), nil, nil)
+	withProjects(t, list, Config{})
+	saved := fetchConcurrency
+	fetchConcurrency = 8
+	t.Cleanup(func() { fetchConcurrency = saved })
+
+	c := &collection{Projects: list}
+	if err := fetchStage(c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Sources) != 40 {
+		t.Fatalf("got %d sources, want 40", len(c.Sources))
+	}
+	for i, s := range c.Sources {
+		if want := fmt.Sprintf("project%d", i); s.Project != want {
+			t.Errorf("source %d is %s, want %s", i, s.Project, want)
+		}
+	}
+	if len(c.Failures) != 1 || c.Failures[0].Project != "missing" {
+		t.Errorf("got failures %+v, want the one of missing", c.Failures)
+	}
+}
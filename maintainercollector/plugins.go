@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// defaultPluginTimeout is the time a plugin may run for by default.
+const defaultPluginTimeout = time.Minute
+
+// runPlugin runs an exec plugin, an exporter or a source, with input on its
+// standard input and env added to its environment, and returns its standard
+// output. Its standard error is logged, prefixed with name.
+func runPlugin(name string, command []string, timeout string, env []string, input []byte) ([]byte, error) {
+	d := defaultPluginTimeout
+	if timeout != "" {
+		var err error
+		if d, err = time.ParseDuration(timeout); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	logOutput(name, stderr.Bytes())
+	if ctx.Err() == context.DeadlineExceeded {
+		return stdout.Bytes(), fmt.Errorf("timed out after %s", d)
+	}
+	return stdout.Bytes(), err
+}
+
+// logOutput logs each line of the output of a plugin.
+func logOutput(name string, out []byte) {
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			logrus.Infof("%s: %s", name, line)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// ghOAuthUri is the base URL of the GitHub OAuth endpoints.
+var ghOAuthUri = "https://github.com"
+
+// sessionDuration is how long a portal login lasts.
+const sessionDuration = 12 * time.Hour
+
+// portal lets maintainers update their own contact details: they log in
+// with GitHub OAuth and each update opens pull requests against the
+// MAINTAINERS files listing them. It is enabled with serve -portal.
+//
+//	GET  <prefix>/portal/login     redirects to GitHub to log in
+//	GET  <prefix>/portal/callback  completes the login
+//	POST <prefix>/portal/contact   updates the name and email form values, with
+//	                               an X-Portal-Request header
+//
+// The cookies of the portal are SameSite=Lax, and updates require the
+// X-Portal-Request header, which forms of other sites cannot set, against
+// cross-site request forgery.
+type portal struct {
+	clientID     string
+	clientSecret string
+
+	// key signs the session cookies; sessions do not survive restarts.
+	key []byte
+
+	prefix   string
+	projects []string
+
+	// home is where users are sent once logged in, if set.
+	home string
+}
+
+// newPortal returns the portal of a tenant. The OAuth application is read
+// from the GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET environment variables.
+func newPortal(s *server) (*portal, error) {
+	p := &portal{
+		clientID:     os.Getenv("GITHUB_CLIENT_ID"),
+		clientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+		key:          make([]byte, 32),
+		prefix:       s.prefix() + "/portal/",
+		projects:     s.projects,
+	}
+	if p.clientID == "" || p.clientSecret == "" {
+		return nil, fmt.Errorf("the portal requires GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET")
+	}
+	if _, err := rand.Read(p.key); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// register adds the endpoints of the portal to mux.
+func (p *portal) register(mux *http.ServeMux) {
+	mux.HandleFunc(p.prefix+"login", p.serveLogin)
+	mux.HandleFunc(p.prefix+"callback", p.serveCallback)
+	mux.HandleFunc(p.prefix+"contact", p.serveContact)
+}
+
+func (p *portal) serveLogin(w http.ResponseWriter, r *http.Request) {
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "portal_state", Value: hex.EncodeToString(state), Path: p.prefix,
+		HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode, MaxAge: 600})
+
+	q := url.Values{"client_id": {p.clientID}, "state": {hex.EncodeToString(state)}}
+	http.Redirect(w, r, ghOAuthUri+"/login/oauth/authorize?"+q.Encode(), http.StatusFound)
+}
+
+func (p *portal) serveCallback(w http.ResponseWriter, r *http.Request) {
+	state, err := r.Cookie("portal_state")
+	if err != nil || state.Value == "" || state.Value != r.URL.Query().Get("state") {
+		http.Error(w, "invalid login state, please log in again", http.StatusBadRequest)
+		return
+	}
+
+	login, err := p.oauthLogin(r.URL.Query().Get("code"))
+	if err != nil {
+		logrus.Errorf("portal: login failed: %v", err)
+		http.Error(w, "login with GitHub failed", http.StatusUnauthorized)
+		return
+	}
+
+	expires := time.Now().Add(sessionDuration)
+	http.SetCookie(w, &http.Cookie{Name: "portal_session", Value: p.session(login, expires), Path: p.prefix,
+		HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode, Expires: expires})
+	logrus.Infof("portal: %s logged in", login)
+	if p.home != "" {
+		http.Redirect(w, r, p.home, http.StatusFound)
+		return
+	}
+	fmt.Fprintf(w, "Logged in as %s. POST your name and email to %scontact, with an X-Portal-Request header, to update them.\n", login, p.prefix)
+}
+
+// oauthLogin exchanges an OAuth code for a token and returns the GitHub
+// login of its owner.
+func (p *portal) oauthLogin(code string) (string, error) {
+	req, err := http.NewRequest("POST", ghOAuthUri+"/login/oauth/access_token", strings.NewReader(url.Values{
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code":          {code},
+	}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := doJSON(req, &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no access token: %s", token.Error)
+	}
+
+	req, err = http.NewRequest("GET", ghApiUri+"/user", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "token "+token.AccessToken)
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := doJSON(req, &user); err != nil {
+		return "", err
+	}
+	if user.Login == "" {
+		return "", fmt.Errorf("no login in the GitHub user")
+	}
+	return user.Login, nil
+}
+
+// doJSON sends req and decodes its JSON response into v.
+func doJSON(req *http.Request, v interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// session returns the signed value of the session cookie of login.
+func (p *portal) session(login string, expires time.Time) string {
+	v := login + "|" + strconv.FormatInt(expires.Unix(), 10)
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(v))
+	return v + "|" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sessionLogin returns the GitHub login of the session of r, if valid.
+func (p *portal) sessionLogin(r *http.Request) (string, bool) {
+	c, err := r.Cookie("portal_session")
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(c.Value, "|")
+	if len(parts) != 3 {
+		return "", false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().After(time.Unix(expires, 0)) {
+		return "", false
+	}
+	if !hmac.Equal([]byte(c.Value), []byte(p.session(parts[0], time.Unix(expires, 0)))) {
+		return "", false
+	}
+	return parts[0], true
+}
+
+func (p *portal) serveContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST the name and email form values", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("X-Portal-Request") == "" {
+		http.Error(w, "missing X-Portal-Request header", http.StatusForbidden)
+		return
+	}
+	login, ok := p.sessionLogin(r)
+	if !ok {
+		http.Error(w, "not logged in, see "+p.prefix+"login", http.StatusUnauthorized)
+		return
+	}
+
+	name, email := strings.TrimSpace(r.FormValue("name")), strings.TrimSpace(r.FormValue("email"))
+	if name == "" && email == "" {
+		http.Error(w, "nothing to update, set name or email", http.StatusBadRequest)
+		return
+	}
+	if email != "" {
+		if a, err := mail.ParseAddress(email); err != nil || a.Address != email {
+			http.Error(w, "invalid email address", http.StatusBadRequest)
+			return
+		}
+	}
+
+	pulls, err := p.updateContact(login, name, email)
+	if err != nil {
+		logrus.Errorf("portal: updating the contact of %s failed: %v", login, err)
+		http.Error(w, "updating your contact details failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"pulls": pulls})
+}
+
+// updateContact opens a pull request against every project listing login in
+// its People, replacing its name and email with the given ones, if not
+// empty. It returns the URLs of the pull requests.
+func (p *portal) updateContact(login, name, email string) ([]string, error) {
+	var pulls []string
+	for _, proj := range p.projects {
+		org, project := getProjectOrg(proj)
+		file, err := getRepoFile(org, project, "MAINTAINERS")
+		if err != nil {
+			logrus.Warnf("portal: %v", err)
+			continue
+		}
+
+		var current MaintainersDepreciated
+		if _, err := toml.Decode(string(file.Content), &current); err != nil {
+			logrus.Warnf("portal: %s/%s: parsing MAINTAINERS file failed: %v", org, project, err)
+			continue
+		}
+
+		updated := string(file.Content)
+		for nick, person := range current.People {
+			if !strings.EqualFold(person.GitHub, login) {
+				continue
+			}
+			if name != "" {
+				person.Name = name
+			}
+			if email != "" {
+				person.Email = email
+			}
+			if person == current.People[nick] {
+				continue
+			}
+			if updated, err = updatePerson(updated, nick, person); err != nil {
+				return pulls, fmt.Errorf("%s/%s: %v", org, project, err)
+			}
+		}
+		if updated == string(file.Content) {
+			continue
+		}
+
+		title := fmt.Sprintf("Update the contact details of %s", login)
+		body := fmt.Sprintf("@%s updated their contact details through the maintainers portal.\n", login)
+		pr, err := openPullRequest(org, project, file, uniqueBranch("update-contact-"+strings.ToLower(login)), title, body, updated)
+		if err != nil {
+			return pulls, err
+		}
+		logrus.Infof("portal: %s/%s: opened %s for %s", org, project, pr, login)
+		pulls = append(pulls, pr)
+	}
+	return pulls, nil
+}
+
+// updatePerson replaces the Name and Email of the People entry of nick in
+// the contents of a MAINTAINERS file. The rest of the file is left
+// untouched.
+func updatePerson(file string, nick string, person Person) (string, error) {
+	header := regexp.MustCompile(`(?mi)^[ \t]*\[people\.("?)` + regexp.QuoteMeta(nick) + `"?\][ \t]*$`)
+	loc := header.FindStringIndex(file)
+	if loc == nil {
+		return "", fmt.Errorf("cannot find the [People.%s] section", nick)
+	}
+	end := len(file)
+	if next := regexp.MustCompile(`(?m)^[ \t]*\[`).FindStringIndex(file[loc[1]:]); next != nil {
+		end = loc[1] + next[0]
+	}
+
+	entry := file[loc[1]:end]
+	for _, f := range []struct{ key, value string }{{"Name", person.Name}, {"Email", person.Email}} {
+		re := regexp.MustCompile(`(?mi)^([ \t]*` + f.key + `[ \t]*=[ \t]*)"[^"\n]*"`)
+		if !re.MatchString(entry) {
+			return "", fmt.Errorf("[People.%s] has no %s", nick, f.key)
+		}
+		value := strings.Replace(fmt.Sprintf("%q", f.value), "$", "$$", -1)
+		entry = re.ReplaceAllString(entry, "${1}"+value)
+	}
+	return file[:loc[1]] + entry + file[end:], nil
+}
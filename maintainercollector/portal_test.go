@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPortalCSRF(t *testing.T) {
+	p := &portal{clientID: "id", key: []byte("key"), prefix: "/portal/"}
+
+	w := httptest.NewRecorder()
+	p.serveLogin(w, httptest.NewRequest("GET", "/portal/login", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("got login cookies %+v, want a SameSite=Lax state cookie", cookies)
+	}
+
+	session := &http.Cookie{Name: "portal_session", Value: p.session("alice", time.Now().Add(time.Hour))}
+	for _, tt := range []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusForbidden},
+		// the header passes, the empty form is rejected
+		{"1", http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest("POST", "/portal/contact", nil)
+		r.AddCookie(session)
+		if tt.header != "" {
+			r.Header.Set("X-Portal-Request", tt.header)
+		}
+		w := httptest.NewRecorder()
+		p.serveContact(w, r)
+		if w.Code != tt.want {
+			t.Errorf("X-Portal-Request %q: got %d %s, want %d", tt.header, w.Code, w.Body, tt.want)
+		}
+	}
+}
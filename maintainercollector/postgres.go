@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// PostgresSink loads the combined maintainers into a Postgres database after
+// each run, in a normalized schema BI tools can query:
+//
+//	people       one row per person, upserted by nick
+//	projects     one row per Org section, upserted by name
+//	memberships  the people listed in each section, replaced by each run
+//	roles        the project roles of roles.toml, and role_holders their
+//	             holders
+//	runs         one row per run
+//
+// The SQL is run by psql, in a single transaction, so that the collector
+// needs no database driver.
+type PostgresSink struct {
+	// URL is the connection string passed to psql. Keep the password out
+	// of it, in PGPASSWORD or ~/.pgpass.
+	URL string
+
+	// Psql is the psql program and its extra arguments, psql by default.
+	Psql []string
+
+	// Timeout, as accepted by time.ParseDuration, defaults to one minute.
+	Timeout string
+}
+
+// postgresSchema creates the tables of the sink, if needed.
+const postgresSchema = `CREATE TABLE IF NOT EXISTS runs (
+    id bigserial PRIMARY KEY,
+    finished_at timestamptz NOT NULL,
+    projects integer NOT NULL,
+    people integer NOT NULL
+);
+CREATE TABLE IF NOT EXISTS people (
+    nick text PRIMARY KEY,
+    name text NOT NULL DEFAULT '',
+    email text NOT NULL DEFAULT '',
+    github text NOT NULL DEFAULT '',
+    company text NOT NULL DEFAULT '',
+    last_run bigint REFERENCES runs (id)
+);
+CREATE TABLE IF NOT EXISTS projects (
+    name text PRIMARY KEY,
+    title text NOT NULL DEFAULT '',
+    special boolean NOT NULL DEFAULT false,
+    last_run bigint REFERENCES runs (id)
+);
+CREATE TABLE IF NOT EXISTS memberships (
+    project text NOT NULL REFERENCES projects (name) ON DELETE CASCADE,
+    nick text NOT NULL,
+    PRIMARY KEY (project, nick)
+);
+CREATE TABLE IF NOT EXISTS roles (
+    name text PRIMARY KEY,
+    text text NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS role_holders (
+    role text NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    nick text NOT NULL,
+    PRIMARY KEY (role, nick)
+);
+`
+
+func init() {
+	if err := registerStage(stage{"postgres", postgresStage}, "export"); err != nil {
+		panic(err)
+	}
+}
+
+// postgresStage loads the combined maintainers into the Postgres sink of the
+// configuration, if any. Failures are logged and do not fail the run.
+func postgresStage(c *collection) error {
+	p := config.Postgres
+	if p == nil {
+		return nil
+	}
+
+	psql := p.Psql
+	if len(psql) == 0 {
+		psql = []string{"psql"}
+	}
+	command := append(append([]string{}, psql...), "--no-psqlrc", "--quiet", "--set", "ON_ERROR_STOP=1", "--single-transaction", p.URL)
+
+	out, err := runPlugin("postgres", command, p.Timeout, nil, postgresSQL(c.Maintainers, time.Now().UTC()))
+	logOutput("postgres", out)
+	if err != nil {
+		logrus.Errorf("postgres: %v", err)
+		return nil
+	}
+	logrus.Infof("postgres: loaded %d projects and %d people", len(c.Maintainers.Org), len(c.Maintainers.People))
+	return nil
+}
+
+// postgresSQL returns the SQL loading m into the sink: people and projects
+// are upserted, memberships and role holders replaced.
+func postgresSQL(m Maintainers, finished time.Time) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString(postgresSchema)
+
+	fmt.Fprintf(buf, "INSERT INTO runs (finished_at, projects, people) VALUES (%s, %d, %d);\n",
+		sqlString(finished.Format(time.RFC3339)), len(m.Projects()), len(m.People))
+	const run = "currval(pg_get_serial_sequence('runs', 'id'))"
+
+	var nicks []string
+	for nick := range m.People {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+	for _, nick := range nicks {
+		p := m.People[nick]
+		fmt.Fprintf(buf, "INSERT INTO people (nick, name, email, github, company, last_run) VALUES (%s, %s, %s, %s, %s, %s)"+
+			" ON CONFLICT (nick) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email, github = EXCLUDED.github,"+
+			" company = EXCLUDED.company, last_run = EXCLUDED.last_run;\n",
+			sqlString(nick), sqlString(p.Name), sqlString(p.Email), sqlString(p.GitHub), sqlString(p.Company), run)
+	}
+
+	var sections []string
+	for name := range m.Org {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+	buf.WriteString("DELETE FROM memberships;\n")
+	for _, name := range sections {
+		o := m.Org[name]
+		fmt.Fprintf(buf, "INSERT INTO projects (name, title, special, last_run) VALUES (%s, %s, %t, %s)"+
+			" ON CONFLICT (name) DO UPDATE SET title = EXCLUDED.title, special = EXCLUDED.special, last_run = EXCLUDED.last_run;\n",
+			sqlString(name), sqlString(o.Title), specialOrgs[name], run)
+		for _, nick := range removeDuplicates(o.People) {
+			fmt.Fprintf(buf, "INSERT INTO memberships (project, nick) VALUES (%s, %s);\n", sqlString(name), sqlString(nick))
+		}
+	}
+
+	roles := projectRoles()
+	var names []string
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf.WriteString("DELETE FROM role_holders;\n")
+	for _, name := range names {
+		r := roles[name]
+		fmt.Fprintf(buf, "INSERT INTO roles (name, text) VALUES (%s, %s) ON CONFLICT (name) DO UPDATE SET text = EXCLUDED.text;\n",
+			sqlString(name), sqlString(strings.TrimSpace(r.Text)))
+		var holders []string
+		if r.Person != "" {
+			holders = append(holders, r.Person)
+		}
+		for _, nick := range removeDuplicates(append(holders, r.People...)) {
+			fmt.Fprintf(buf, "INSERT INTO role_holders (role, nick) VALUES (%s, %s);\n", sqlString(name), sqlString(strings.ToLower(nick)))
+		}
+	}
+	return buf.Bytes()
+}
+
+// sqlString quotes s as an SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profiles are named sets of settings stored in a TOML file, selected with
+// -profile, for operators working against several GitHub instances:
+//
+//	[Profile.ghes]
+//	API = "https://github.example.com/api/v3"
+//	Raw = "https://github.example.com/raw"
+//	TokenEnv = "GHES_TOKEN"
+//	Org = "platform"
+//	Config = "/etc/maintainercollector/ghes.toml"
+//	JSON = true
+//
+// Flags given on the command line take precedence over the profile.
+
+// Profile holds the settings of a profile. Empty fields keep the defaults.
+type Profile struct {
+	// API and Raw are the base URLs of the GitHub API and raw files.
+	API string
+	Raw string
+
+	// TokenEnv is the environment variable holding the GitHub token,
+	// instead of GITHUB_TOKEN; TokenFile is a file holding it.
+	TokenEnv  string
+	TokenFile string
+
+	// Org is the organization of the projects given without one.
+	Org string
+
+	// Config is the configuration file, as with -config.
+	Config string
+
+	// JSON and Gzip set -json and -gzip.
+	JSON bool
+	Gzip bool
+}
+
+// defaultProfilesFile returns the default location of the profiles file.
+func defaultProfilesFile() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "maintainercollector", "profiles.toml")
+}
+
+// loadProfile reads the profile name from the profiles file at path.
+func loadProfile(path, name string) (Profile, error) {
+	var f struct {
+		Profile map[string]Profile
+	}
+	if err := decodeFile(path, &f); err != nil {
+		return Profile{}, err
+	}
+	p, ok := f.Profile[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%s: no profile %q", path, name)
+	}
+	return p, nil
+}
+
+// apply applies the profile to the global settings, except those set by the
+// flags on the command line. configFile is the value of -config.
+func (p Profile) apply(configFile *string) error {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if p.API != "" {
+		ghApiUri = strings.TrimRight(p.API, "/")
+	}
+	if p.Raw != "" {
+		ghRawUri = strings.TrimRight(p.Raw, "/")
+	}
+	if p.Org != "" {
+		defaultOrg = p.Org
+	}
+	if p.Config != "" && !set["config"] {
+		*configFile = p.Config
+	}
+	if p.JSON && !set["json"] {
+		writeJSON = true
+	}
+	if p.Gzip && !set["gzip"] {
+		compressArtifacts = true
+	}
+
+	switch {
+	case p.TokenFile != "":
+		b, err := ioutil.ReadFile(p.TokenFile)
+		if err != nil {
+			return err
+		}
+		token := strings.TrimSpace(string(b))
+		ghToken = func() string { return token }
+	case p.TokenEnv != "":
+		ghToken = func() string { return os.Getenv(p.TokenEnv) }
+	}
+	return nil
+}
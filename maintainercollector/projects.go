@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// projectsCmd implements the projects command.
+func projectsCmd(args []string) error {
+	fs := flag.NewFlagSet("projects", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "only report the state of the last runs, without fetching")
+	fs.Parse(args)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tORG\tBRANCH\tLAST FETCH\tFORMAT\tMAINTAINERS")
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+
+		if !*offline {
+			if m, err := getMaintainers(org, project); err != nil {
+				logrus.Errorf("%s/%s: %v", org, project, err)
+			} else {
+				runState.fetched(org, project, m, "", "")
+			}
+		}
+
+		s, ok := runState.project(org, project)
+		if !ok || s.LastFetch.IsZero() {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", project, org, "-", "never", "-", "-")
+			continue
+		}
+
+		branch := s.Branch
+		if !*offline {
+			var repo struct {
+				DefaultBranch string `json:"default_branch"`
+			}
+			if err := githubGet(fmt.Sprintf("/repos/%s/%s", org, project), &repo); err != nil {
+				logrus.Warnf("%s/%s: resolving default branch failed: %v", org, project, err)
+			} else if repo.DefaultBranch != s.Branch {
+				branch = fmt.Sprintf("%s (default: %s)", s.Branch, repo.DefaultBranch)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", project, org, branch, s.LastFetch.Format(time.RFC3339), s.Format, s.Maintainers)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// pruneCandidate is a tracked project suggested for removal from the
+// project list.
+type pruneCandidate struct {
+	Project string
+	Reason  string
+}
+
+// pruneCmd implements the prune command.
+func pruneCmd(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	runs := fs.Int("runs", 3, "suggest pruning projects whose MAINTAINERS file was missing, or empty, for this many consecutive runs")
+	archived := fs.Bool("archived", true, "also suggest pruning archived and deleted repositories, checked with the GitHub API")
+	pr := fs.String("pr", "", "open a pull request pruning the Projects of the configuration file at this path of a repository (org/project/path)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: prune [options]\n\n"+
+			"Suggests removing the tracked projects that are archived, deleted, or have had no MAINTAINERS file\n"+
+			"or no maintainers for several runs, as recorded in the -state file.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if statePath == "" {
+		logrus.Warn("prune: without -state, only archived and deleted repositories are found")
+	}
+
+	var candidates []pruneCandidate
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		if reason := pruneReason(org, project, *runs, *archived); reason != "" {
+			candidates = append(candidates, pruneCandidate{Project: p, Reason: reason})
+		}
+	}
+	if len(candidates) == 0 {
+		logrus.Info("prune: no project to prune")
+		return nil
+	}
+
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "The following projects should no longer be tracked by the maintainers collector:\n\n")
+	for _, c := range candidates {
+		fmt.Fprintf(body, "- %s: %s\n", c.Project, c.Reason)
+	}
+
+	if *pr == "" {
+		fmt.Printf("%s\nSuggested project list:\n\n%s", body, suggestedProjects(projects, candidates))
+		return nil
+	}
+
+	parts := strings.SplitN(*pr, "/", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("prune: -pr: expected org/project/path, got %q", *pr)
+	}
+	file, err := getRepoFile(parts[0], parts[1], parts[2])
+	if err != nil {
+		return err
+	}
+	var remove []string
+	for _, c := range candidates {
+		remove = append(remove, c.Project)
+	}
+	updated, err := pruneProjects(string(file.Content), remove)
+	if err != nil {
+		return fmt.Errorf("prune: %s: %v", *pr, err)
+	}
+	url, err := openPullRequest(parts[0], parts[1], file, uniqueBranch("prune-projects"), "Prune the tracked projects", body.String(), updated)
+	if err != nil {
+		return err
+	}
+	fmt.Println(url)
+	return nil
+}
+
+// pruneReason returns why org/project should be pruned, or an empty string.
+func pruneReason(org, project string, runs int, archived bool) string {
+	if s, ok := runState.project(org, project); ok {
+		switch {
+		case s.MissingRuns >= runs:
+			return fmt.Sprintf("no MAINTAINERS file for the last %d runs", s.MissingRuns)
+		case s.EmptyRuns >= runs:
+			return fmt.Sprintf("no maintainers listed for the last %d runs", s.EmptyRuns)
+		}
+	}
+	if !archived {
+		return ""
+	}
+
+	var repo struct {
+		Archived bool `json:"archived"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s", org, project), &repo); err != nil {
+		if errorClass(err) == classMissing {
+			return "the repository does not exist"
+		}
+		logrus.Warnf("%s/%s: %v", org, project, err)
+		return ""
+	}
+	if repo.Archived {
+		return "the repository is archived"
+	}
+	return ""
+}
+
+// suggestedProjects returns the Projects setting of the configuration file
+// without the candidates.
+func suggestedProjects(list []string, candidates []pruneCandidate) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("Projects = [\n")
+	for _, p := range list {
+		pruned := false
+		for _, c := range candidates {
+			pruned = pruned || c.Project == p
+		}
+		if !pruned {
+			fmt.Fprintf(buf, "    %q,\n", p)
+		}
+	}
+	buf.WriteString("]\n")
+	return buf.String()
+}
+
+var projectsSetting = regexp.MustCompile(`(?m)^[ \t]*Projects[ \t]*=[ \t]*\[[^\]]*\]`)
+
+// pruneProjects removes the given entries from the Projects setting of the
+// configuration file content, keeping the rest of the file as is.
+func pruneProjects(content string, remove []string) (string, error) {
+	loc := projectsSetting.FindStringIndex(content)
+	if loc == nil {
+		return "", fmt.Errorf("no Projects setting")
+	}
+	setting := content[loc[0]:loc[1]]
+	for _, p := range remove {
+		entry := regexp.MustCompile(`\n?[ \t]*"` + regexp.QuoteMeta(p) + `"[ \t]*,?`)
+		if !entry.MatchString(setting) {
+			return "", fmt.Errorf("%s is not in the Projects setting", p)
+		}
+		setting = entry.ReplaceAllString(setting, "")
+	}
+	return content[:loc[0]] + setting + content[loc[1]:], nil
+}
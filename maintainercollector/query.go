@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// queryResult is the answer to a query against the history store.
+type queryResult struct {
+	Query    string    `json:"query"`
+	AsOf     time.Time `json:"as_of"`
+	Snapshot time.Time `json:"snapshot"`
+	Results  []string  `json:"results"`
+}
+
+// queryCmd implements the query command.
+func queryCmd(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	asOf := fs.String("as-of", "", "answer as of this date (2006-01-02, midnight UTC) or time (RFC 3339) (default: now)")
+	format := fs.String("format", "text", "output format, text or json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: query [options] who-maintains <project> | maintained-by <nick>\n\n"+
+			"Answers questions about the maintainers at a point in time, from the history store given with -history.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("query: expected a question and its argument")
+	}
+	if historyFile == "" {
+		return fmt.Errorf("query: requires -history")
+	}
+	at, err := parseAsOf(*asOf)
+	if err != nil {
+		return err
+	}
+
+	history, err := loadHistory(historyFile)
+	if err != nil {
+		return err
+	}
+	s, ok := snapshotAt(history, at)
+	if !ok {
+		return fmt.Errorf("query: the history store has no snapshot before %s", at.Format(time.RFC3339))
+	}
+
+	r := queryResult{Query: strings.Join(fs.Args(), " "), AsOf: at, Snapshot: s.Time, Results: []string{}}
+	switch question, arg := fs.Arg(0), fs.Arg(1); question {
+	case "who-maintains":
+		_, project := getProjectOrg(arg)
+		people, ok := s.Projects[sectionName(project)]
+		if !ok {
+			return fmt.Errorf("query: %s was not tracked as of %s", project, at.Format(time.RFC3339))
+		}
+		r.Results = append(r.Results, people...)
+	case "maintained-by":
+		nick := strings.ToLower(arg)
+		for project, people := range s.Projects {
+			for _, p := range people {
+				if p == nick {
+					r.Results = append(r.Results, project)
+				}
+			}
+		}
+		sort.Strings(r.Results)
+	default:
+		return fmt.Errorf("query: unknown question %q, expected who-maintains or maintained-by", question)
+	}
+
+	if *format == "json" {
+		b, err := json.MarshalIndent(r, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(b, '\n'))
+		return err
+	}
+	fmt.Printf("# %s as of %s (snapshot of %s)\n", r.Query, at.Format(time.RFC3339), s.Time.Format(time.RFC3339))
+	for _, result := range r.Results {
+		fmt.Println(result)
+	}
+	return nil
+}
+
+// parseAsOf parses the -as-of flag of the query command. An empty value is
+// the current time.
+func parseAsOf(v string) (time.Time, error) {
+	if v == "" {
+		return time.Now().UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, fmt.Errorf("query: invalid -as-of %q, expected 2006-01-02 or an RFC 3339 time", v)
+	}
+	return t, nil
+}
+
+// snapshotAt returns the snapshot of the history in effect at t: the last
+// one taken at or before t. The history must be sorted, oldest first.
+func snapshotAt(history []snapshot, t time.Time) (snapshot, bool) {
+	i := sort.Search(len(history), func(i int) bool { return history[i].Time.After(t) })
+	if i == 0 {
+		return snapshot{}, false
+	}
+	return history[i-1], true
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// quorumCmd implements the quorum command.
+func quorumCmd(args []string) error {
+	fs := flag.NewFlagSet("quorum", flag.ExitOnError)
+	list := fs.String("approvals", "", "comma separated list of nicks or GitHub handles that approved")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quorum <project> -approvals a,b,c\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// allow flags after the project as well
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return fmt.Errorf("quorum: expected a project")
+	}
+	p := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("quorum: unexpected arguments %v", fs.Args())
+	}
+
+	org, project := getProjectOrg(p)
+	current, err := getMaintainers(org, project)
+	if err != nil {
+		return err
+	}
+	_, people := maintainersSection(current)
+	if len(people) == 0 {
+		return fmt.Errorf("%s/%s: no maintainers found", org, project)
+	}
+
+	voters := voterNicks(current, people)
+	var approvers []string
+	for _, a := range strings.Split(*list, ",") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if nick, ok := voters[a]; ok {
+			a = nick
+		}
+		if !containsFold(approvers, a) {
+			approvers = append(approvers, a)
+		}
+	}
+
+	required := requiredApprovals(project, people)
+	passed, reasons := required.tally(approvers)
+
+	fmt.Printf("%s/%s\n", org, project)
+	for _, r := range reasons {
+		fmt.Printf("    %s\n", r)
+	}
+	if !passed {
+		fmt.Println("FAIL")
+		return fmt.Errorf("%s/%s: quorum not met", org, project)
+	}
+	fmt.Println("PASS")
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Views of the maintainers served by the serve command.
+const (
+	viewPublic   = "public"
+	viewInternal = "internal"
+)
+
+// redactionPolicy defines which fields a view of the served maintainers
+// leaves out or adds.
+type redactionPolicy struct {
+	// RedactEmails leaves out the email address of every person.
+	RedactEmails bool
+
+	// Findings adds the governance findings of the last regeneration to the
+	// JSON file.
+	Findings bool
+}
+
+// redactionPolicies holds the policy of each view: the public view only has
+// the names and GitHub handles of people, the internal view also has their
+// email addresses and the governance audit.
+var redactionPolicies = map[string]redactionPolicy{
+	viewPublic:   {RedactEmails: true},
+	viewInternal: {Findings: true},
+}
+
+// apply returns a copy of m, along with findings, projected by the policy.
+func (p redactionPolicy) apply(m Maintainers, findings []finding) Maintainers {
+	if p.RedactEmails {
+		people := make(map[string]Person, len(m.People))
+		for nick, person := range m.People {
+			person.Email = ""
+			people[nick] = person
+		}
+		m.People = people
+	}
+	if p.Findings {
+		m.Findings = findings
+	}
+	return m
+}
+
+// requestView returns the view a request is allowed to see, given the API
+// tokens of the configuration. Requests without a known token get the public
+// view; if no token is configured, every request gets the internal view.
+func requestView(r *http.Request, tokens map[string]string) string {
+	if len(tokens) == 0 {
+		return viewInternal
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return viewPublic
+	}
+	given := []byte(strings.TrimPrefix(auth, "Bearer "))
+	for token, view := range tokens {
+		if subtle.ConstantTimeCompare(given, []byte(token)) == 1 {
+			return view
+		}
+	}
+	return viewPublic
+}
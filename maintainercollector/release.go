@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// releaseAsset is a file of a release.
+type releaseAsset struct {
+	Name string
+	Data []byte
+}
+
+var rosterTemplate = template.Must(template.New("roster").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Maintainers {{.Version}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+td, th { text-align: left; padding: 0.1em 1em 0.1em 0; }
+</style>
+</head>
+<body>
+<h1>Maintainers {{.Version}}</h1>
+{{range .Projects}}
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>Nick</th><th>Name</th><th>GitHub</th></tr>
+{{range .People}}<tr><td>{{.Nick}}</td><td>{{.Name}}</td><td>{{if .GitHub}}<a href="https://github.com/{{.GitHub}}">@{{.GitHub}}</a>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// releaseCmd implements the release command.
+func releaseCmd(args []string) error {
+	fs := flag.NewFlagSet("release", flag.ExitOnError)
+	version := fs.String("version", "", "version of the release (default: the date, as v2006.01.02)")
+	output := fs.String("o", "", "directory to write the release to (default: release-<version>)")
+	signKey := fs.String("sign-key", "", "sign the checksums with the hex encoded Ed25519 private key (or seed) in this file")
+	upload := fs.String("upload", "", "upload the release to this repository (org/project) as a GitHub release")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: release [options]\n\n"+
+			"Bundles the combined maintainers as TOML, JSON and HTML, with their SHA-256 checksums\n"+
+			"and optionally a signature, into a versioned release.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *version == "" {
+		*version = time.Now().UTC().Format("v2006.01.02")
+	}
+	if *output == "" {
+		*output = "release-" + *version
+	}
+
+	c, err := runPipeline("")
+	if err != nil {
+		return err
+	}
+	assets, err := releaseAssets(c, *version)
+	if err != nil {
+		return err
+	}
+
+	sums := new(bytes.Buffer)
+	for _, a := range assets {
+		sum := sha256.Sum256(a.Data)
+		fmt.Fprintf(sums, "%s  %s\n", hex.EncodeToString(sum[:]), a.Name)
+	}
+	assets = append(assets, releaseAsset{"SHA256SUMS", sums.Bytes()})
+
+	if *signKey != "" {
+		key, err := loadSigningKey(*signKey)
+		if err != nil {
+			return err
+		}
+		sig := ed25519.Sign(key, sums.Bytes())
+		assets = append(assets, releaseAsset{"SHA256SUMS.sig", []byte(hex.EncodeToString(sig) + "\n")})
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		return err
+	}
+	for _, a := range assets {
+		if err := ioutil.WriteFile(filepath.Join(*output, a.Name), a.Data, 0644); err != nil {
+			return err
+		}
+	}
+	logrus.Infof("wrote release %s to %s", *version, *output)
+
+	if *upload != "" {
+		return uploadRelease(*upload, *version, assets)
+	}
+	return nil
+}
+
+// releaseAssets encodes the combined maintainers of c as the files of a
+// release.
+func releaseAssets(c *collection, version string) ([]releaseAsset, error) {
+	m := c.Maintainers
+	json, err := encodeMaintainersJSON(m)
+	if err != nil {
+		return nil, err
+	}
+	emails, err := encodeEmailIndex(m)
+	if err != nil {
+		return nil, err
+	}
+
+	type rosterPerson struct{ Nick, Name, GitHub string }
+	type rosterProject struct {
+		Title  string
+		People []rosterPerson
+	}
+	data := struct {
+		Version  string
+		Projects []rosterProject
+	}{Version: version}
+	for _, name := range m.Projects() {
+		p := rosterProject{Title: name}
+		if t := m.Org[name].Title; t != "" {
+			p.Title = t
+		}
+		for _, nick := range m.Org[name].People {
+			person := m.People[nick]
+			p.People = append(p.People, rosterPerson{nick, person.Name, person.GitHub})
+		}
+		data.Projects = append(data.Projects, p)
+	}
+	html := new(bytes.Buffer)
+	if err := rosterTemplate.Execute(html, data); err != nil {
+		return nil, err
+	}
+
+	return []releaseAsset{
+		{"MAINTAINERS", c.File},
+		{"MAINTAINERS.json", json},
+		{"MAINTAINERS.by-email.json", emails},
+		{"MAINTAINERS.html", html.Bytes()},
+	}, nil
+}
+
+// loadSigningKey reads a hex encoded Ed25519 private key, or its 32 byte
+// seed, from path.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	switch {
+	case err != nil:
+	case len(key) == ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case len(key) == ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	}
+	return nil, fmt.Errorf("%s: expected a hex encoded Ed25519 private key or seed", path)
+}
+
+// uploadRelease creates the GitHub release version in repo, with assets as
+// its files.
+func uploadRelease(repo, version string, assets []releaseAsset) error {
+	org, project := getProjectOrg(repo)
+	var release struct {
+		HTMLURL   string `json:"html_url"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/releases", org, project), map[string]string{
+		"tag_name": version,
+		"name":     "Maintainers " + version,
+		"body":     "Combined maintainers of all projects, with the SHA-256 checksums of the files.",
+	}, &release); err != nil {
+		return fmt.Errorf("%s/%s: creating release %s failed: %v", org, project, version, err)
+	}
+
+	// the upload URL is a URI template, e.g. ".../assets{?name,label}"
+	uploadURL := release.UploadURL
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	for _, a := range assets {
+		if err := uploadAsset(uploadURL, a); err != nil {
+			return fmt.Errorf("%s/%s: uploading %s failed: %v", org, project, a.Name, err)
+		}
+	}
+	logrus.Infof("published release %s", release.HTMLURL)
+	return nil
+}
+
+// uploadAsset uploads a file of a release to its upload URL.
+func uploadAsset(uploadURL string, a releaseAsset) error {
+	req, err := http.NewRequest("POST", uploadURL+"?name="+url.QueryEscape(a.Name), bytes.NewReader(a.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if token := ghToken(); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
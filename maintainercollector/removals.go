@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// inactiveMaintainer is a maintainer without activity since the threshold.
+type inactiveMaintainer struct {
+	Nick     string
+	GitHub   string
+	Activity activity
+}
+
+// proposeRemovalsCmd implements the propose-removals command.
+func proposeRemovalsCmd(args []string) error {
+	fs := flag.NewFlagSet("propose-removals", flag.ExitOnError)
+	days := fs.Int("days", 90, "number of days without activity after which a maintainer is inactive")
+	issue := fs.Bool("issue", false, "open an issue instead of a pull request")
+	dryRun := fs.Bool("dry-run", false, "print the proposals instead of opening them")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: propose-removals [options] [project...]\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = projects
+	}
+	since := time.Now().AddDate(0, 0, -*days)
+
+	for _, p := range targets {
+		org, project := getProjectOrg(p)
+		if err := proposeRemovals(org, project, since, *days, *issue, *dryRun); err != nil {
+			logrus.Errorf("%s/%s: %v", org, project, err)
+		}
+	}
+	return nil
+}
+
+// proposeRemovals drafts a pull request (or issue) removing the maintainers
+// of a project that have been inactive since the given time.
+func proposeRemovals(org, project string, since time.Time, days int, issue, dryRun bool) error {
+	file, err := getRepoFile(org, project, "MAINTAINERS")
+	if err != nil {
+		return err
+	}
+
+	var current MaintainersDepreciated
+	if _, err := toml.Decode(string(file.Content), &current); err != nil {
+		return fmt.Errorf("parsing MAINTAINERS file failed: %v", err)
+	}
+	section, people := maintainersSection(current)
+	if section == "" {
+		return fmt.Errorf("MAINTAINERS file has no maintainers section")
+	}
+
+	handles := map[string]string{}
+	for nick, person := range current.People {
+		handles[strings.ToLower(nick)] = person.GitHub
+	}
+
+	var inactive []inactiveMaintainer
+	for _, nick := range people {
+		handle := handles[strings.ToLower(nick)]
+		if handle == "" {
+			handle = nick
+		}
+		a, err := getActivity(org, project, handle)
+		if err != nil {
+			return fmt.Errorf("fetching activity of %s failed: %v", handle, err)
+		}
+		if a.Last().Before(since) {
+			inactive = append(inactive, inactiveMaintainer{Nick: nick, GitHub: handle, Activity: a})
+		}
+	}
+
+	if len(inactive) == 0 {
+		logrus.Infof("%s/%s: no maintainers inactive for more than %d days", org, project, days)
+		return nil
+	}
+
+	title := "Remove inactive maintainers"
+	body := removalEvidence(org, project, inactive, days, requiredApprovals(project, people))
+
+	if dryRun {
+		fmt.Printf("# %s/%s: %s\n\n%s\n", org, project, title, body)
+		return nil
+	}
+
+	var url string
+	if issue {
+		var created struct {
+			HTMLURL string `json:"html_url"`
+		}
+		if err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/issues", org, project), map[string]string{
+			"title": title,
+			"body":  body,
+		}, &created); err != nil {
+			return fmt.Errorf("opening issue failed: %v", err)
+		}
+		url = created.HTMLURL
+	} else {
+		updated, err := editSectionPeople(string(file.Content), section, func(nicks []string) []string {
+			var kept []string
+			for _, n := range nicks {
+				if !isInactive(inactive, n) {
+					kept = append(kept, n)
+				}
+			}
+			return kept
+		})
+		if err != nil {
+			return err
+		}
+		if url, err = openPullRequest(org, project, file, uniqueBranch("remove-inactive-maintainers"), title, body, updated); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(url)
+	logrus.Infof("%s/%s: proposed removal of %d inactive maintainers in %s", org, project, len(inactive), url)
+	return nil
+}
+
+// removalEvidence formats the Markdown body of a removal proposal.
+func removalEvidence(org, project string, inactive []inactiveMaintainer, days int, a approvals) string {
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return t.Format("2006-01-02")
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "The following maintainers of %s/%s have shown no activity in the last %d days:\n\n", org, project, days)
+	fmt.Fprintf(buf, "| Maintainer | Last commit | Last comment | Last review |\n")
+	fmt.Fprintf(buf, "|------------|-------------|--------------|-------------|\n")
+	for _, m := range inactive {
+		fmt.Fprintf(buf, "| @%s | %s | %s | %s |\n", m.GitHub, date(m.Activity.LastCommit), date(m.Activity.LastComment), date(m.Activity.LastReview))
+	}
+	fmt.Fprintf(buf, "\nPer the project governance (see \"Removal of inactive maintainers\"), they should first be "+
+		"asked whether they want to continue being a maintainer. Removing a maintainer who wants to stay requires %s.\n", a)
+	return buf.String()
+}
+
+func isInactive(inactive []inactiveMaintainer, nick string) bool {
+	for _, m := range inactive {
+		if strings.EqualFold(m.Nick, nick) {
+			return true
+		}
+	}
+	return false
+}
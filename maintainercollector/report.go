@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status of a project in the run report.
+const (
+	statusFetched = "fetched" // downloaded during this run
+	statusCached  = "cached"  // unchanged since the last run, read from the cache
+	statusStale   = "stale"   // failed to load, last-known-good copy used
+	statusFailed  = "failed"  // failed to load and left out of the output
+)
+
+// runReport summarizes a run of the collector. It is written to the file
+// given with -report.
+type runReport struct {
+	mu sync.Mutex
+
+	Started  time.Time       `json:"started"`
+	Finished time.Time       `json:"finished"`
+	Projects []projectReport `json:"projects"`
+	Findings []finding       `json:"findings,omitempty"`
+
+	// Failures counts the projects that failed to load, stale ones
+	// included, by class of failure (see fetcherrors.go).
+	Failures map[string]int `json:"failures,omitempty"`
+	Gates    []gateResult   `json:"gates,omitempty"`
+
+	// total counts the failures of all the runs of the process, by class,
+	// for the metrics of serve. It is not reset between runs.
+	total map[string]int
+}
+
+// projectReport is the outcome of loading the MAINTAINERS file of a project.
+type projectReport struct {
+	Org     string `json:"org"`
+	Project string `json:"project"`
+	Status  string `json:"status"`
+	Source  string `json:"source,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Class   string `json:"class,omitempty"`
+}
+
+// report is the report of the current run. It is reset at the start of
+// each run of the pipeline, so that the runs of serve, for every tenant,
+// only report their own projects.
+var report = &runReport{Started: time.Now().UTC()}
+
+// reset clears the report for a new run.
+func (r *runReport) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Started, r.Finished = time.Now().UTC(), time.Time{}
+	r.Projects, r.Findings, r.Failures, r.Gates = nil, nil, nil, nil
+}
+
+// project records the outcome of loading a project, and the source it was
+// fetched from, if any. err may be nil.
+func (r *runReport) project(org, project, status, source string, err error) {
+	p := projectReport{Org: org, Project: project, Status: status, Source: source}
+	if err != nil {
+		p.Error, p.Class = err.Error(), errorClass(err)
+	}
+
+	r.mu.Lock()
+	r.Projects = append(r.Projects, p)
+	if p.Class != "" {
+		if r.Failures == nil {
+			r.Failures = map[string]int{}
+		}
+		r.Failures[p.Class]++
+		if r.total == nil {
+			r.total = map[string]int{}
+		}
+		r.total[p.Class]++
+	}
+	r.mu.Unlock()
+}
+
+// save writes the report to path.
+func (r *runReport) save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Finished = time.Now().UTC()
+	sort.SliceStable(r.Projects, func(i, j int) bool {
+		return r.Projects[i].Org+"/"+r.Projects[i].Project < r.Projects[j].Org+"/"+r.Projects[j].Project
+	})
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
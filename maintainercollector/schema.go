@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeFile decodes the TOML file at path into v, after checking it against
+// the schema given by the type of v. Unknown keys and values of the wrong
+// type are all reported at once, each with its full key, instead of the
+// first decoding failure. Byte order marks and CRLF line endings are
+// accepted, see normalizeText.
+func decodeFile(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data := string(normalizeText(b))
+
+	var raw map[string]interface{}
+	if _, err := toml.Decode(data, &raw); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	if problems := checkSchema("", reflect.TypeOf(v), raw); len(problems) > 0 {
+		return fmt.Errorf("%s is invalid:\n\t%s", path, strings.Join(problems, "\n\t"))
+	}
+
+	if _, err := toml.Decode(data, v); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// checkSchema returns the problems found checking the decoded TOML value v,
+// found at key, against the Go type t it is decoded into.
+func checkSchema(key string, t reflect.Type, v interface{}) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	mismatch := func() []string {
+		return []string{fmt.Sprintf("%s: expected %s, found %s", displayKey(key), schemaType(t), tomlType(v))}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		table, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		fields := map[string]reflect.StructField{}
+		var names []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("toml"), ",")[0]
+			if name == "-" || f.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields[strings.ToLower(name)] = f
+			names = append(names, name)
+		}
+
+		var problems []string
+		for _, k := range sortedKeys(table) {
+			f, ok := fields[strings.ToLower(k)]
+			if !ok {
+				p := fmt.Sprintf("%s: unknown key", displayKey(join(key, k)))
+				if s := suggest(k, names); s != "" {
+					p += fmt.Sprintf(", did you mean %s?", s)
+				}
+				problems = append(problems, p)
+				continue
+			}
+			problems = append(problems, checkSchema(join(key, k), f.Type, table[k])...)
+		}
+		return problems
+
+	case reflect.Map:
+		table, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		var problems []string
+		for _, k := range sortedKeys(table) {
+			problems = append(problems, checkSchema(join(key, k), t.Elem(), table[k])...)
+		}
+		return problems
+
+	case reflect.Slice:
+		rv := reflect.ValueOf(v)
+		if v == nil || rv.Kind() != reflect.Slice {
+			return mismatch()
+		}
+		var problems []string
+		for i := 0; i < rv.Len(); i++ {
+			problems = append(problems, checkSchema(fmt.Sprintf("%s[%d]", key, i), t.Elem(), rv.Index(i).Interface())...)
+		}
+		return problems
+
+	case reflect.String:
+		switch v.(type) {
+		case string:
+		case int64, float64:
+			p := mismatch()
+			p[0] += fmt.Sprintf(", quote it: \"%v\"", v)
+			return p
+		default:
+			return mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := v.(int64); !ok {
+			return mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v.(type) {
+		case float64, int64:
+		default:
+			return mismatch()
+		}
+	}
+	return nil
+}
+
+// schemaType describes the TOML values accepted for the Go type t.
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "a table"
+	case reflect.Slice:
+		return "an array"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean (true or false)"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return "an integer"
+	}
+}
+
+// tomlType describes the decoded TOML value v.
+func tomlType(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return "a table"
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case bool:
+		return fmt.Sprintf("the boolean %v", v)
+	case int64:
+		return fmt.Sprintf("the integer %d", v)
+	case float64:
+		return fmt.Sprintf("the number %v", v)
+	case time.Time:
+		return "a date"
+	default:
+		if reflect.ValueOf(v).Kind() == reflect.Slice {
+			return "an array"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// join appends k to the dotted key, quoting it if needed.
+func join(key, k string) string {
+	if strings.ContainsAny(k, ". \"") {
+		k = fmt.Sprintf("%q", k)
+	}
+	if key == "" {
+		return k
+	}
+	return key + "." + k
+}
+
+// displayKey returns key for messages, naming the top level table.
+func displayKey(key string) string {
+	if key == "" {
+		return "top level"
+	}
+	return key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggest returns the candidate closest to the misspelled key, if close
+// enough to be a likely typo.
+func suggest(key string, candidates []string) string {
+	best, bestDist := "", len(key)/3+2
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// securityPolicyPaths are the paths tried, in order, for the security policy
+// of a project.
+var securityPolicyPaths = []string{"SECURITY.md", ".github/SECURITY.md", "docs/SECURITY.md"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+
+// securityRouting is the security-routing file read by the vulnerability
+// intake tooling.
+type securityRouting struct {
+	Generated time.Time       `json:"generated"`
+	Projects  []securityRoute `json:"projects"`
+}
+
+// securityRoute lists where to report vulnerabilities of a project: the
+// contacts of its security policy first, then its maintainers.
+type securityRoute struct {
+	Project     string            `json:"project"`
+	Repository  string            `json:"repository"`
+	Policy      string            `json:"policy,omitempty"`
+	Contacts    []string          `json:"contacts"`
+	Maintainers []securityContact `json:"maintainers"`
+}
+
+// securityContact is a maintainer of a project in the security-routing file.
+type securityContact struct {
+	Nick  string `json:"nick"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// securityRoutingCmd implements the security-routing command.
+func securityRoutingCmd(args []string) error {
+	fs := flag.NewFlagSet("security-routing", flag.ExitOnError)
+	output := fs.String("o", "security-routing.json", "write the routing file to this file, - for the standard output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: security-routing [options]\n\n"+
+			"Writes the security contacts of each project, from its SECURITY.md and its maintainers,\n"+
+			"and fails if a project has no way to be reached.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	routing := buildSecurityRouting(collectMaintainers())
+	b, err := json.MarshalIndent(routing, "", "    ")
+	if err != nil {
+		return fmt.Errorf("JSON encoding error: %v", err)
+	}
+	b = append(b, '\n')
+	if *output == "-" {
+		os.Stdout.Write(b)
+	} else if err := ioutil.WriteFile(*output, b, 0644); err != nil {
+		return err
+	}
+
+	if gaps := securityGaps(routing); len(gaps) > 0 {
+		for _, g := range gaps {
+			logrus.Errorf("security-routing: %s", g)
+		}
+		return fmt.Errorf("security-routing: %d projects cannot be reached", len(gaps))
+	}
+	return nil
+}
+
+// buildSecurityRouting returns the security routes of the collected
+// projects, fetching their security policy.
+func buildSecurityRouting(m Maintainers) securityRouting {
+	routing := securityRouting{Generated: time.Now().UTC()}
+	seen := map[string]bool{}
+	for _, p := range collectedProjects() {
+		org, project := getProjectOrg(p)
+		name := sectionName(project)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		r := securityRoute{Project: name, Repository: org + "/" + project, Contacts: []string{}, Maintainers: []securityContact{}}
+		for _, path := range securityPolicyPaths {
+			file, err := getRawFile(org, project, path)
+			if err != nil {
+				continue
+			}
+			r.Policy = path
+			r.Contacts = policyContacts(file)
+			break
+		}
+		if r.Policy == "" {
+			logrus.Warnf("%s/%s: no security policy, routing to the maintainers only", org, project)
+		}
+
+		if o, ok := m.Org[name]; ok {
+			for _, nick := range o.People {
+				p := m.People[nick]
+				r.Maintainers = append(r.Maintainers, securityContact{Nick: nick, Name: p.Name, Email: p.Email})
+			}
+		}
+		routing.Projects = append(routing.Projects, r)
+	}
+	return routing
+}
+
+// policyContacts returns the email addresses of a security policy, in order
+// of appearance.
+func policyContacts(policy []byte) []string {
+	contacts := []string{}
+	for _, email := range emailPattern.FindAllString(string(policy), -1) {
+		email = strings.ToLower(email)
+		if !containsFold(contacts, email) {
+			contacts = append(contacts, email)
+		}
+	}
+	return contacts
+}
+
+// securityGaps returns the projects of the routing that no one can be
+// contacted for: with no security policy contact and no maintainer email.
+func securityGaps(routing securityRouting) []string {
+	var gaps []string
+	for _, r := range routing.Projects {
+		if len(r.Contacts) > 0 {
+			continue
+		}
+		reachable := false
+		for _, c := range r.Maintainers {
+			if c.Email != "" {
+				reachable = true
+				break
+			}
+		}
+		if reachable {
+			continue
+		}
+		if len(r.Maintainers) == 0 {
+			gaps = append(gaps, fmt.Sprintf("%s has no security policy contact and no maintainers", r.Project))
+		} else {
+			gaps = append(gaps, fmt.Sprintf("%s has no security policy contact and no maintainer email", r.Project))
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	httppprof "net/http/pprof"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// server regenerates the combined MAINTAINERS file of a tenant and serves
+// its latest version. The default tenant has no name and uses the global
+// configuration.
+type server struct {
+	name     string
+	config   Config
+	projects []string
+	interval time.Duration
+	output   string
+	history  string
+
+	// maxAge is the Cache-Control max-age of the public snapshot.
+	maxAge time.Duration
+
+	// trigger requests a regeneration before the end of the interval, see
+	// serveGitHubHook.
+	trigger    chan struct{}
+	deliveries deliveries
+
+	// mu guards the fields below, written by regenerate and read by the
+	// handlers.
+	mu        sync.RWMutex
+	views     map[string]*rendered
+	generated time.Time
+
+	// last is the result of the last regeneration, to notify the webhooks
+	// of the changes.
+	last *Maintainers
+
+	// queues are the pending notifications of each webhook, only used by
+	// regenerate.
+	queues []*webhookQueue
+}
+
+// rendered holds the files served for a view.
+type rendered struct {
+	file        []byte
+	gzipped     []byte
+	json        []byte
+	gzippedJSON []byte
+
+	// etag is the entity tag of json.
+	etag string
+}
+
+// serveCmd implements the serve command.
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	interval := fs.Duration("interval", time.Hour, "time between two regenerations")
+	profiling := fs.Bool("pprof", false, "expose the net/http/pprof endpoints under /debug/pprof/")
+	verifications := fs.String("verifications", "", "serve the links sent by verify-contacts under /verify, recording confirmations in this file")
+	withPortal := fs.Bool("portal", false, "serve the maintainers self-service portal under /portal/ (requires GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET)")
+	withConsole := fs.Bool("console", false, "serve the governance console under /portal/console/ (implies -portal)")
+	tenants := fs.String("tenants", "", "host the tenants defined in this file, each under /<tenant>/, instead of the -config collection")
+	maxAge := fs.Duration("max-age", 5*time.Minute, "time clients and CDNs may cache the public snapshot for")
+	fs.Parse(args)
+
+	servers := []*server{{config: config, projects: projects, interval: *interval, history: historyFile}}
+	if *tenants != "" {
+		var err error
+		if servers, err = loadTenants(*tenants, *interval); err != nil {
+			return err
+		}
+	}
+	for _, s := range servers {
+		s.maxAge = *maxAge
+		s.trigger = make(chan struct{}, 1)
+	}
+
+	mux := http.NewServeMux()
+	for _, s := range servers {
+		go s.run()
+		mux.HandleFunc(s.prefix()+"/MAINTAINERS", s.serveMaintainers)
+		mux.HandleFunc(s.prefix()+"/MAINTAINERS.json", s.serveMaintainersJSON)
+		mux.HandleFunc(s.prefix()+"/v1/maintainers.json", s.serveSnapshot)
+		mux.HandleFunc(s.prefix()+"/v1/reviewers", s.serveReviewers)
+		mux.HandleFunc(s.prefix()+"/openapi.json", serveSpec)
+		if len(s.config.GitHubWebhookSecrets) > 0 {
+			mux.HandleFunc(s.prefix()+"/github-webhook", s.serveGitHubHook)
+		}
+		if *withPortal || *withConsole {
+			p, err := newPortal(s)
+			if err != nil {
+				return err
+			}
+			p.register(mux)
+			if *withConsole {
+				newConsole(s, p).register(mux)
+			}
+		}
+	}
+	mux.HandleFunc("/metrics", serveMetrics)
+	if *verifications != "" {
+		store, err := loadVerifications(*verifications)
+		if err != nil {
+			return err
+		}
+		mux.HandleFunc("/verify", store.serveVerify)
+	}
+	if *profiling {
+		mux.HandleFunc("/debug/pprof/", httppprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	}
+
+	logrus.Infof("serving on %s", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// prefix returns the URL prefix the files of the tenant are served under.
+func (s *server) prefix() string {
+	if s.name == "" {
+		return ""
+	}
+	return "/" + s.name
+}
+
+// run regenerates the file of the tenant at every interval, or sooner when
+// triggered.
+func (s *server) run() {
+	for {
+		s.regenerate()
+		select {
+		case <-time.After(s.interval):
+		case <-s.trigger:
+		}
+	}
+}
+
+// regenerate collects the MAINTAINERS files of the tenant and replaces the
+// served files of every view. On failure, the previous files keep being
+// served.
+func (s *server) regenerate() {
+	collectMu.Lock()
+	savedConfig, savedProjects := config, projects
+	config, projects = s.config, s.projects
+	c, err := runPipeline("audit")
+	config, projects = savedConfig, savedProjects
+	collectMu.Unlock()
+	if err != nil {
+		logrus.Errorf("%sregenerating MAINTAINERS failed: %v", s.logPrefix(), err)
+		return
+	}
+	m, findings := c.Maintainers, c.Findings
+
+	views := map[string]*rendered{}
+	for view, policy := range redactionPolicies {
+		r, err := render(policy.apply(m, findings))
+		if err != nil {
+			logrus.Errorf("%sregenerating the %s view failed: %v", s.logPrefix(), view, err)
+			return
+		}
+		views[view] = r
+	}
+
+	if s.output != "" {
+		if err := writeArtifact(filepath.Join(s.output, "MAINTAINERS"), views[viewInternal].file, 0644, s.config.Encodings["MAINTAINERS"]); err != nil {
+			logrus.Errorf("%swriting MAINTAINERS failed: %v", s.logPrefix(), err)
+		}
+	}
+
+	if s.history != "" {
+		if err := recordHistory(s.history, m, findings, c.Failures); err != nil {
+			logrus.Errorf("%s%s: recording history failed: %v", s.logPrefix(), s.history, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.views, s.generated = views, time.Now()
+	last := s.last
+	s.last = &m
+	s.mu.Unlock()
+	logrus.Infof("%sregenerated combined MAINTAINERS file", s.logPrefix())
+
+	if last != nil && len(s.config.Webhooks) > 0 {
+		notifyWebhooks(s.config.Webhooks, &s.queues, s.name, *last, m)
+	}
+}
+
+// render encodes the files served for m.
+func render(m Maintainers) (*rendered, error) {
+	file, err := encodeMaintainers(m)
+	if err != nil {
+		return nil, err
+	}
+	gzipped, err := gzipBytes(file)
+	if err != nil {
+		return nil, err
+	}
+	json, err := encodeMaintainersJSON(m)
+	if err != nil {
+		return nil, err
+	}
+	gzippedJSON, err := gzipBytes(json)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(json)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	return &rendered{file: file, gzipped: gzipped, json: json, gzippedJSON: gzippedJSON, etag: etag}, nil
+}
+
+// view returns the files of the view r is allowed to see, or nil if they
+// were not generated yet.
+func (s *server) view(r *http.Request) (*rendered, time.Time) {
+	view := requestView(r, s.config.APITokens)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.views[view], s.generated
+}
+
+// logPrefix returns the prefix of the log messages about the tenant.
+func (s *server) logPrefix() string {
+	if s.name == "" {
+		return ""
+	}
+	return "tenant " + s.name + ": "
+}
+
+func (s *server) serveMaintainers(w http.ResponseWriter, r *http.Request) {
+	v, generated := s.view(r)
+	if v == nil {
+		http.Error(w, "MAINTAINERS file not generated yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	file := v.file
+	w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+	w.Header().Set("Last-Modified", generated.UTC().Format(http.TimeFormat))
+	w.Header().Set("Vary", "Accept-Encoding, Authorization")
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		file = v.gzipped
+	}
+	w.Write(file)
+}
+
+func (s *server) serveMaintainersJSON(w http.ResponseWriter, r *http.Request) {
+	v, generated := s.view(r)
+	if v == nil {
+		http.Error(w, "MAINTAINERS file not generated yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	file := v.json
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Last-Modified", generated.UTC().Format(http.TimeFormat))
+	w.Header().Set("Vary", "Accept-Encoding, Authorization")
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		file = v.gzippedJSON
+	}
+	w.Write(file)
+}
+
+// serveSnapshot serves the public view of the latest combined maintainers as
+// JSON, whatever the credentials of the request, at a stable URL clients and
+// CDNs can cache: responses carry an ETag, and conditional requests matching
+// it get 304 Not Modified.
+func (s *server) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	v, generated := s.views[viewPublic], s.generated
+	s.mu.RUnlock()
+	if v == nil {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "MAINTAINERS file not generated yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("ETag", v.etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.maxAge.Seconds())))
+	w.Header().Set("Last-Modified", generated.UTC().Format(http.TimeFormat))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if etagMatches(r.Header.Get("If-None-Match"), v.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method == "HEAD" {
+		return
+	}
+	w.Write(v.json)
+}
+
+// serveMetrics serves the fetch failures since the start of the server, by
+// class, in the Prometheus text format.
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	report.mu.Lock()
+	var classes []string
+	for class := range report.total {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# HELP maintainercollector_fetch_failures_total MAINTAINERS files that failed to load, by class of failure.\n")
+	fmt.Fprintf(buf, "# TYPE maintainercollector_fetch_failures_total counter\n")
+	for _, class := range classes {
+		fmt.Fprintf(buf, "maintainercollector_fetch_failures_total{class=%q} %d\n", class, report.total[class])
+	}
+	report.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+// serveSpec serves the OpenAPI specification of the API.
+func serveSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write([]byte(openapi))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the client accepts gzip encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.SplitN(enc, ";", 2)
+		if len(parts) == 2 && strings.Replace(parts[1], " ", "", -1) == "q=0" {
+			continue
+		}
+		if enc := strings.TrimSpace(parts[0]); enc == "gzip" || enc == "*" {
+			return true
+		}
+	}
+	return false
+}
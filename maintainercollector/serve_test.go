@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/opensource/maintainercollector/clients/clientstest"
+)
+
+// renderedServer returns a server serving m as its internal view.
+func renderedServer(t *testing.T, m Maintainers) *server {
+	r, err := render(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &server{views: map[string]*rendered{viewInternal: r}, generated: time.Now()}
+}
+
+func TestServeMaintainersJSONGzip(t *testing.T) {
+	s := renderedServer(t, roster("cli", "alice"))
+	want := s.views[viewInternal].json
+
+	w := httptest.NewRecorder()
+	s.serveMaintainersJSON(w, httptest.NewRequest("GET", "/MAINTAINERS.json", nil))
+	if w.Header().Get("Content-Encoding") != "" || !bytes.Equal(w.Body.Bytes(), want) {
+		t.Errorf("without Accept-Encoding: got %q encoded as %q, want the plain JSON", w.Body, w.Header().Get("Content-Encoding"))
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/MAINTAINERS.json", nil)
+	r.Header.Set("Accept-Encoding", "gzip, deflate")
+	s.serveMaintainersJSON(w, r)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("with Accept-Encoding gzip: got Content-Encoding %q", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("with Accept-Encoding gzip: got %q, want the JSON", got)
+	}
+}
+
+// TestServeDuringRegenerate requests the files of a server while it
+// regenerates them. Run it with -race.
+func TestServeDuringRegenerate(t *testing.T) {
+	list, f := fakeProjects(10)
+	n := &clientstest.Notifier{}
+	useMocks(t, answerGitHub(nil), serveFiles(f), n, nil)
+	s := &server{projects: list, config: Config{Webhooks: []Webhook{{URL: "https://example.com/hook"}}}}
+
+	handlers := map[string]http.HandlerFunc{
+		"/MAINTAINERS":                             s.serveMaintainers,
+		"/MAINTAINERS.json":                        s.serveMaintainersJSON,
+		"/v1/maintainers.json":                     s.serveSnapshot,
+		"/v1/reviewers?project=project1&path=a.go": s.serveReviewers,
+		"/metrics":                                 serveMetrics,
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for path, h := range handlers {
+		wg.Add(1)
+		go func(path string, h http.HandlerFunc) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				h(w, httptest.NewRequest("GET", path, nil))
+				if w.Code != http.StatusOK && w.Code != http.StatusServiceUnavailable {
+					t.Errorf("%s: got %d %s", path, w.Code, w.Body)
+					return
+				}
+			}
+		}(path, h)
+	}
+
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			// a change to notify to the webhook
+			f["docker/project0/MAINTAINERS"] = f["docker/project1/MAINTAINERS"]
+		}
+		s.regenerate()
+	}
+	close(done)
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	s.serveMaintainersJSON(w, httptest.NewRequest("GET", "/MAINTAINERS.json", nil))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte("user9")) {
+		t.Errorf("got %d %s, want the combined maintainers", w.Code, w.Body)
+	}
+	if len(n.NotifyCalls) != 1 {
+		t.Errorf("got %d webhook notifications, want 1", len(n.NotifyCalls))
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Source is an external program providing the maintainers of a project
+// whose data does not live in a MAINTAINERS file of a Git repository. It is
+// run with MAINTAINERCOLLECTOR_ORG and MAINTAINERCOLLECTOR_PROJECT set and
+// must write the maintainers to its standard output as JSON:
+//
+//	{
+//	    "maintainers": ["nick", ...],
+//	    "curators": ["nick", ...],
+//	    "docs": ["nick", ...],
+//	    "people": {"nick": {"name": "...", "email": "...", "github": "..."}}
+//	}
+//
+// Projects with a source are collected along with the configured projects;
+// if they are also listed there, the source replaces their MAINTAINERS file.
+type Source struct {
+	// Project is "org/project" or, for projects of the default org,
+	// "project".
+	Project string
+
+	// Command is the program and its arguments.
+	Command []string
+
+	// Timeout, as accepted by time.ParseDuration, defaults to one minute.
+	Timeout string
+}
+
+// sourceOutput is the output of a source.
+type sourceOutput struct {
+	Maintainers []string `json:"maintainers"`
+	Curators    []string `json:"curators"`
+	Docs        []string `json:"docs"`
+	People      map[string]struct {
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		GitHub string `json:"github"`
+	} `json:"people"`
+}
+
+// collectedProjects returns the configured projects followed by the
+// projects of the sources not already listed. Projects listed twice are
+// only returned once.
+func collectedProjects() []string {
+	listed := map[string]bool{}
+	var all []string
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		key := strings.ToLower(org + "/" + project)
+		if listed[key] {
+			logrus.Warnf("%s/%s is listed twice in the projects, collecting it once", org, project)
+			continue
+		}
+		listed[key] = true
+		all = append(all, p)
+	}
+
+	for _, s := range config.Sources {
+		org, project := getProjectOrg(s.Project)
+		if key := strings.ToLower(org + "/" + project); !listed[key] {
+			all = append(all, s.Project)
+			listed[key] = true
+		}
+	}
+	return all
+}
+
+// duplicateProjects checks that no two projects of list, from different
+// orgs, have the same name, e.g. containerd/containerd and a mirror in
+// docker/containerd: they would share an Org section. If merge is set (see
+// MergeDuplicates in the configuration), their maintainers are merged,
+// otherwise it is an error.
+func duplicateProjects(list []string, merge bool) error {
+	orgs := map[string][]string{}
+	var names []string
+	for _, p := range list {
+		org, project := getProjectOrg(p)
+		name := strings.ToLower(project)
+		if _, ok := orgs[name]; !ok {
+			names = append(names, name)
+		}
+		orgs[name] = append(orgs[name], org+"/"+project)
+	}
+
+	var dups []string
+	for _, name := range names {
+		if repos := orgs[name]; len(repos) > 1 {
+			dups = append(dups, strings.Join(repos, " and "))
+		}
+	}
+	if len(dups) == 0 {
+		return nil
+	}
+	if merge {
+		for _, d := range dups {
+			logrus.Warnf("merging the maintainers of %s, listed under the same name", d)
+		}
+		return nil
+	}
+	return fmt.Errorf("projects listed under the same name: %s; remove the mirrors or set MergeDuplicates", strings.Join(dups, "; "))
+}
+
+// projectSourcePlugin returns the source of a project, if any.
+func projectSourcePlugin(org, project string) (Source, bool) {
+	for _, s := range config.Sources {
+		if o, p := getProjectOrg(s.Project); strings.EqualFold(o, org) && strings.EqualFold(p, project) {
+			return s, true
+		}
+	}
+	return Source{}, false
+}
+
+// loadFromSource runs the source of a project and returns its maintainers,
+// and records the outcome in the run report.
+func loadFromSource(org, project string, s Source) (MaintainersDepreciated, error) {
+	m, err := runSource(org, project, s)
+	if err != nil {
+		err = fmt.Errorf("%s/%s: source %s: %v", org, project, s.Command[0], err)
+		report.project(org, project, statusFailed, "", err)
+		return m, err
+	}
+	runState.fetched(org, project, m, "", "")
+	report.project(org, project, statusFetched, "exec", nil)
+	return m, nil
+}
+
+// runSource runs the source of a project and converts its output.
+func runSource(org, project string, s Source) (MaintainersDepreciated, error) {
+	out, err := runPlugin(fmt.Sprintf("%s/%s: source", org, project), s.Command, s.Timeout,
+		[]string{"MAINTAINERCOLLECTOR_ORG=" + org, "MAINTAINERCOLLECTOR_PROJECT=" + project}, nil)
+	if err != nil {
+		return MaintainersDepreciated{}, err
+	}
+
+	var o sourceOutput
+	if err := json.Unmarshal(out, &o); err != nil {
+		return MaintainersDepreciated{}, fmt.Errorf("parsing output failed: %v", err)
+	}
+
+	m := MaintainersDepreciated{
+		Organization: Organization{Maintainers: &Org{People: o.Maintainers}},
+		People:       map[string]Person{},
+	}
+	if len(o.Curators) > 0 {
+		m.Organization.Curators = &Org{People: o.Curators}
+	}
+	if len(o.Docs) > 0 {
+		m.Organization.DocsMaintainers = &Org{People: o.Docs}
+	}
+	for nick, p := range o.People {
+		m.People[nick] = Person{Name: p.Name, Email: p.Email, GitHub: p.GitHub}
+	}
+	return m, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// soleMaintainer is a person who is the only maintainer of several projects.
+type soleMaintainer struct {
+	Nick     string
+	Projects []string
+}
+
+// coveredProject is a project whose maintainers all maintain another project.
+type coveredProject struct {
+	Project string
+	By      string
+	People  []string
+}
+
+// spofCmd implements the spof command.
+func spofCmd(args []string) error {
+	fs := flag.NewFlagSet("spof", flag.ExitOnError)
+	fs.Parse(args)
+
+	sole, covered := singlePointsOfFailure(collectMaintainers())
+
+	fmt.Println("People who are the sole maintainer of two or more projects:")
+	if len(sole) == 0 {
+		fmt.Println("    none")
+	}
+	for _, s := range sole {
+		fmt.Printf("    %s: %s\n", s.Nick, strings.Join(s.Projects, ", "))
+	}
+
+	fmt.Println("\nProjects whose maintainers all maintain another project:")
+	if len(covered) == 0 {
+		fmt.Println("    none")
+	}
+	for _, c := range covered {
+		fmt.Printf("    %s: all maintainers (%s) also maintain %s\n", c.Project, strings.Join(c.People, ", "), c.By)
+	}
+	return nil
+}
+
+// singlePointsOfFailure finds the people who are the sole maintainer of two
+// or more projects, and the projects whose maintainers are all maintainers of
+// another project, so that losing a few people would orphan both.
+func singlePointsOfFailure(m Maintainers) ([]soleMaintainer, []coveredProject) {
+	names := m.Projects()
+
+	soleOf := map[string][]string{}
+	for _, p := range names {
+		if people := m.Org[p].People; len(people) == 1 {
+			soleOf[people[0]] = append(soleOf[people[0]], p)
+		}
+	}
+	var sole []soleMaintainer
+	for nick, projects := range soleOf {
+		if len(projects) >= 2 {
+			sole = append(sole, soleMaintainer{Nick: nick, Projects: projects})
+		}
+	}
+	sort.Slice(sole, func(i, j int) bool { return sole[i].Nick < sole[j].Nick })
+
+	var covered []coveredProject
+	for _, p := range names {
+		people := m.Org[p].People
+		if len(people) == 0 {
+			continue
+		}
+		for _, other := range names {
+			if other == p || !isSubset(people, m.Org[other].People) {
+				continue
+			}
+			// report identical rosters only once
+			if len(people) == len(m.Org[other].People) && other < p {
+				continue
+			}
+			covered = append(covered, coveredProject{Project: p, By: other, People: people})
+		}
+	}
+
+	return sole, covered
+}
+
+// isSubset reports whether every element of a is in b.
+func isSubset(a, b []string) bool {
+	for _, e := range a {
+		if !containsFold(b, e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// projectState is what the collector remembers about a project between runs.
+type projectState struct {
+	Org         string    `json:"org"`
+	Project     string    `json:"project"`
+	Branch      string    `json:"branch"`
+	Sha         string    `json:"sha,omitempty"`
+	TreeETag    string    `json:"tree_etag,omitempty"`
+	LastFetch   time.Time `json:"last_fetch"`
+	Format      string    `json:"format"`
+	Maintainers int       `json:"maintainers"`
+
+	// MissingRuns and EmptyRuns count the consecutive runs the
+	// MAINTAINERS file of the project was missing, or listed no
+	// maintainers. See the prune command.
+	MissingRuns int `json:"missing_runs,omitempty"`
+	EmptyRuns   int `json:"empty_runs,omitempty"`
+}
+
+// collectorState is persisted in the file given with -state, see save.
+type collectorState struct {
+	mu sync.Mutex
+
+	// Projects is keyed by "org/project".
+	Projects map[string]*projectState `json:"projects"`
+
+	// Profiles are the checkpointed GitHub profiles of people, keyed by
+	// nick, see enrich.go.
+	Profiles map[string]*profileState `json:"profiles,omitempty"`
+
+	// Handles are the GitHub handles found to exist, keyed by lowercased
+	// handle, with the time they were checked, see gates.go.
+	Handles map[string]time.Time `json:"handles,omitempty"`
+}
+
+// statePath is the -state file, if any.
+var statePath string
+
+// runState is the state of the current run, loaded from and saved to the
+// -state file, if any.
+var runState = &collectorState{Projects: map[string]*projectState{}}
+
+// loadState reads the state file at path. A missing file is an empty state.
+func loadState(path string) (*collectorState, error) {
+	s := &collectorState{Projects: map[string]*projectState{}}
+
+	b, err := files.Load(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	if s.Projects == nil {
+		s.Projects = map[string]*projectState{}
+	}
+	return s, nil
+}
+
+// save writes the state to path. It holds the names and emails of the
+// profiles, so it is written like the other files holding personal data:
+// readable only by its owner, and encrypted if -key-file is given.
+func (s *collectorState) save(path string) error {
+	s.mu.Lock()
+	b, err := json.MarshalIndent(s, "", "    ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return files.Save(path, append(b, '\n'), 0600)
+}
+
+// fetched records a successful fetch of the MAINTAINERS file of a project,
+// with its blob SHA and the ETag of the tree listing it, if known.
+func (s *collectorState) fetched(org, project string, m MaintainersDepreciated, sha, etag string) {
+	_, people := maintainersSection(m)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	empty := 0
+	if len(people) == 0 {
+		empty = 1
+		if p, ok := s.Projects[org+"/"+project]; ok {
+			empty += p.EmptyRuns
+		}
+	}
+	s.Projects[org+"/"+project] = &projectState{
+		Org:         org,
+		Project:     project,
+		Branch:      "master",
+		Sha:         sha,
+		TreeETag:    etag,
+		LastFetch:   time.Now().UTC(),
+		Format:      formatVersion(m),
+		Maintainers: len(people),
+		EmptyRuns:   empty,
+	}
+}
+
+// failed records a failure to load the MAINTAINERS file of a project,
+// counting the consecutive runs it was missing.
+func (s *collectorState) failed(org, project string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.Projects[org+"/"+project]
+	if !ok {
+		p = &projectState{Org: org, Project: project}
+		s.Projects[org+"/"+project] = p
+	}
+	if errorClass(err) == classMissing {
+		p.MissingRuns++
+	} else {
+		p.MissingRuns = 0
+	}
+}
+
+// project returns the state of a project, if any.
+func (s *collectorState) project(org, project string) (*projectState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.Projects[org+"/"+project]
+	return p, ok
+}
+
+// formatVersion names the layout of a project's MAINTAINERS file: "current"
+// files list maintainers in [Org.Maintainers], "legacy" files, following the
+// old docker/docker layout, in [Org."Core maintainers"].
+func formatVersion(m MaintainersDepreciated) string {
+	switch section, _ := maintainersSection(m); section {
+	case "Maintainers":
+		return "current"
+	case "Core maintainers":
+		return "legacy"
+	default:
+		return "unknown"
+	}
+}
+
+// profile returns the checkpointed GitHub profile of a person, if any.
+func (s *collectorState) profile(nick string) (*profileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.Profiles[nick]
+	return p, ok
+}
+
+// checkpointProfile records the GitHub profile of a person.
+func (s *collectorState) checkpointProfile(nick string, p *profileState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Profiles == nil {
+		s.Profiles = map[string]*profileState{}
+	}
+	s.Profiles[nick] = p
+}
+
+// handleVerified reports whether the GitHub handle was found to exist
+// within maxAge.
+func (s *collectorState) handleVerified(handle string, maxAge time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.Handles[strings.ToLower(handle)]
+	return ok && time.Since(t) < maxAge
+}
+
+// verifyHandle records that the GitHub handle exists.
+func (s *collectorState) verifyHandle(handle string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Handles == nil {
+		s.Handles = map[string]time.Time{}
+	}
+	s.Handles[strings.ToLower(handle)] = time.Now().UTC()
+}
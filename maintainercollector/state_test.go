@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStatePrivate checks that a state file written in clear by an earlier
+// version is loaded, and saved back encrypted and readable only by its
+// owner.
+func TestStatePrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := ioutil.WriteFile(path, []byte(`{"projects": {}, "profiles": {"alice": {"github": "alice", "email": "alice@example.com"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := encryptionKey
+	encryptionKey = []byte(strings.Repeat("k", 32))
+	t.Cleanup(func() { encryptionKey = saved })
+
+	s, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want 0600", fi.Mode().Perm())
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "alice@example.com") {
+		t.Error("the state file is not encrypted")
+	}
+
+	s, err = loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := s.profile("alice"); !ok || p.Email != "alice@example.com" {
+		t.Errorf("got profile %+v, want the one of alice", p)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// projectStats are the statistics of a project in the stats report.
+type projectStats struct {
+	Org         string           `json:"org"`
+	Project     string           `json:"project"`
+	Maintainers int              `json:"maintainers"`
+	Response    *responseMetrics `json:"response,omitempty"`
+
+	// OverExtended are the maintainers of the project maintaining more
+	// than MaxProjectsPerPerson projects.
+	OverExtended []string `json:"over_extended,omitempty"`
+}
+
+// responseMetrics measure how fast maintainers respond to new issues and
+// pull requests.
+type responseMetrics struct {
+	Sampled     int     `json:"sampled"`
+	Responded   int     `json:"responded"`
+	MedianHours float64 `json:"median_hours"`
+	P90Hours    float64 `json:"p90_hours"`
+}
+
+// statsCmd implements the stats command.
+func statsCmd(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	days := fs.Int("days", 30, "measure response times of issues and pull requests opened in the last days")
+	sample := fs.Int("sample", 30, "maximum number of issues and pull requests sampled per project")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	noResponse := fs.Bool("no-response", false, "skip the response time metrics, which need many API requests")
+	fs.Parse(args)
+
+	m := collectMaintainers()
+	since := time.Now().AddDate(0, 0, -*days)
+	over := overExtended(m, config.MaxProjectsPerPerson)
+
+	var stats []projectStats
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		o, ok := m.Org[sectionName(project)]
+		if !ok {
+			continue
+		}
+		s := projectStats{Org: org, Project: project, Maintainers: len(o.People)}
+		for _, nick := range o.People {
+			if _, ok := over[nick]; ok {
+				s.OverExtended = append(s.OverExtended, nick)
+			}
+		}
+
+		if !*noResponse {
+			handles := make([]string, len(o.People))
+			for i, nick := range o.People {
+				handles[i] = nick
+				if person, ok := m.People[nick]; ok && person.GitHub != "" {
+					handles[i] = person.GitHub
+				}
+			}
+			r, err := getResponseMetrics(org, project, handles, since, *sample)
+			if err != nil {
+				logrus.Errorf("%s/%s: measuring response times failed: %v", org, project, err)
+			} else {
+				s.Response = r
+			}
+		}
+		stats = append(stats, s)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		return enc.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tMAINTAINERS\tSAMPLED\tRESPONDED\tMEDIAN RESPONSE\tP90 RESPONSE")
+	for _, s := range stats {
+		if s.Response == nil {
+			fmt.Fprintf(w, "%s\t%d\t-\t-\t-\t-\n", s.Project, s.Maintainers)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1fh\t%.1fh\n", s.Project, s.Maintainers,
+			s.Response.Sampled, s.Response.Responded, s.Response.MedianHours, s.Response.P90Hours)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(over) > 0 {
+		var nicks []string
+		for nick := range over {
+			nicks = append(nicks, nick)
+		}
+		sort.Strings(nicks)
+		fmt.Printf("\nMaintainers of more than %d projects:\n", config.MaxProjectsPerPerson)
+		for _, nick := range nicks {
+			fmt.Printf("    %s: %d projects (%s)\n", nick, len(over[nick]), strings.Join(over[nick], ", "))
+		}
+	}
+	return nil
+}
+
+// response is a comment or review on an issue or pull request.
+type response struct {
+	CreatedAt   time.Time `json:"created_at"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	User        struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// getResponseMetrics samples the issues and pull requests of a repository
+// opened since the given time, and measures how long it took one of the
+// maintainers (by GitHub handle) to first comment or review. Issues and pull
+// requests opened by maintainers are not sampled.
+func getResponseMetrics(org, project string, maintainers []string, since time.Time, sample int) (*responseMetrics, error) {
+	var issues []struct {
+		Number      int       `json:"number"`
+		CreatedAt   time.Time `json:"created_at"`
+		PullRequest *struct{} `json:"pull_request"`
+		User        struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/issues?state=all&sort=created&direction=desc&per_page=100&since=%s", org, project, since.UTC().Format(time.RFC3339))
+	if err := githubGet(path, &issues); err != nil {
+		return nil, err
+	}
+
+	r := &responseMetrics{}
+	var delays []time.Duration
+	for _, i := range issues {
+		if r.Sampled >= sample {
+			break
+		}
+		if i.CreatedAt.Before(since) || containsFold(maintainers, i.User.Login) {
+			continue
+		}
+		r.Sampled++
+
+		var responses []response
+		if err := githubGet(fmt.Sprintf("/repos/%s/%s/issues/%d/comments?per_page=100", org, project, i.Number), &responses); err != nil {
+			return nil, err
+		}
+		if i.PullRequest != nil {
+			var reviews []response
+			if err := githubGet(fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews?per_page=100", org, project, i.Number), &reviews); err != nil {
+				return nil, err
+			}
+			// reviews have a submission time instead of a creation time
+			for _, rv := range reviews {
+				rv.CreatedAt = rv.SubmittedAt
+				responses = append(responses, rv)
+			}
+		}
+
+		var first time.Time
+		for _, c := range responses {
+			if containsFold(maintainers, c.User.Login) && (first.IsZero() || c.CreatedAt.Before(first)) {
+				first = c.CreatedAt
+			}
+		}
+		if !first.IsZero() {
+			r.Responded++
+			delays = append(delays, first.Sub(i.CreatedAt))
+		}
+	}
+
+	if len(delays) > 0 {
+		sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+		r.MedianHours = delays[len(delays)/2].Hours()
+		r.P90Hours = delays[(len(delays)*9)/10].Hours()
+	}
+	return r, nil
+}
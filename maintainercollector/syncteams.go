@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// mutation is a change to GitHub made by sync-teams.
+type mutation struct {
+	Method      string
+	Path        string
+	Body        interface{}
+	Description string
+
+	// Destructive mutations remove access and need a confirmation.
+	Destructive bool
+}
+
+// syncTeamsCmd implements the sync-teams command.
+func syncTeamsCmd(args []string) error {
+	fs := flag.NewFlagSet("sync-teams", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only print the changes")
+	confirm := fs.Bool("confirm", false, "apply destructive changes (removals, permission changes) without asking")
+	permission := fs.String("permission", "push", "permission of the teams on the repository of their project")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sync-teams [options]\n\n"+
+			"Makes the members of the GitHub teams of each project (see Teams in the configuration)\n"+
+			"match its maintainers. The changes are printed before being applied.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(config.Teams) == 0 {
+		return fmt.Errorf("sync-teams: no Teams in the configuration")
+	}
+
+	plan, err := planTeamSync(collectMaintainers(), *permission)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("Teams are in sync, nothing to do.")
+		return nil
+	}
+
+	destructive := 0
+	for _, m := range plan {
+		mark := " "
+		if m.Destructive {
+			mark = "!"
+			destructive++
+		}
+		fmt.Printf("%s %-6s %-60s %s\n", mark, m.Method, m.Path, m.Description)
+	}
+	fmt.Printf("\n%d changes, %d destructive (marked !).\n", len(plan), destructive)
+
+	if *dryRun {
+		return nil
+	}
+	if destructive > 0 && !*confirm {
+		ok, err := prompt(fmt.Sprintf("Apply %d destructive changes?", destructive))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("sync-teams: destructive changes not confirmed, use -confirm")
+		}
+	}
+
+	for _, m := range plan {
+		if err := githubRequest(m.Method, m.Path, m.Body, nil); err != nil {
+			return fmt.Errorf("sync-teams: %s: %v", m.Description, err)
+		}
+		logrus.Infof("sync-teams: %s", m.Description)
+	}
+	return nil
+}
+
+// prompt asks a yes or no question on the terminal. It returns false without
+// asking if the standard input is not a terminal.
+func prompt(question string) (bool, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false, nil
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// planTeamSync computes the mutations making the members of the teams of
+// each project match the GitHub handles of its maintainers, and giving the
+// teams permission on the repository of the project. A team shared by
+// several projects gets the maintainers of all of them.
+func planTeamSync(m Maintainers, permission string) ([]mutation, error) {
+	orgs := map[string]string{}
+	for _, p := range projects {
+		org, project := getProjectOrg(p)
+		orgs[project] = org
+	}
+
+	members := map[string][]string{}
+	repos := map[string][]string{}
+	for project, teams := range config.Teams {
+		o, ok := m.Org[sectionName(project)]
+		if !ok {
+			logrus.Warnf("sync-teams: %s has Teams but no maintainers", project)
+			continue
+		}
+		org := orgs[project]
+		if org == "" {
+			org = defaultOrg
+		}
+		for _, team := range teams {
+			for _, nick := range o.People {
+				handle := nick
+				if p, ok := m.People[nick]; ok && p.GitHub != "" {
+					handle = p.GitHub
+				}
+				if !containsFold(members[team], handle) {
+					members[team] = append(members[team], handle)
+				}
+			}
+			repos[team] = append(repos[team], org+"/"+project)
+		}
+	}
+
+	var teams []string
+	for team := range members {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	var plan []mutation
+	for _, team := range teams {
+		p := strings.SplitN(team, "/", 2)
+		if len(p) != 2 {
+			return nil, fmt.Errorf("sync-teams: invalid team %q, expected org/team", team)
+		}
+		org, slug := p[0], p[1]
+		want := members[team]
+		sort.Strings(want)
+
+		var current []string
+		exists := true
+		if err := githubGet(fmt.Sprintf("/orgs/%s/teams/%s", org, slug), nil); errorClass(err) == classMissing {
+			exists = false
+		} else if err != nil {
+			return nil, fmt.Errorf("sync-teams: %s: %v", team, err)
+		}
+		if exists {
+			for page := 1; ; page++ {
+				var users []struct {
+					Login string `json:"login"`
+				}
+				if err := githubGet(fmt.Sprintf("/orgs/%s/teams/%s/members?per_page=100&page=%d", org, slug, page), &users); err != nil {
+					return nil, fmt.Errorf("sync-teams: %s: %v", team, err)
+				}
+				for _, u := range users {
+					current = append(current, u.Login)
+				}
+				if len(users) < 100 {
+					break
+				}
+			}
+		} else {
+			plan = append(plan, mutation{
+				Method:      "POST",
+				Path:        fmt.Sprintf("/orgs/%s/teams", org),
+				Body:        map[string]string{"name": slug, "privacy": "closed"},
+				Description: fmt.Sprintf("create team %s", team),
+			})
+		}
+
+		for _, handle := range want {
+			if !containsFold(current, handle) {
+				plan = append(plan, mutation{
+					Method:      "PUT",
+					Path:        fmt.Sprintf("/orgs/%s/teams/%s/memberships/%s", org, slug, handle),
+					Description: fmt.Sprintf("add %s to %s", handle, team),
+				})
+			}
+		}
+		for _, handle := range current {
+			if !containsFold(want, handle) {
+				plan = append(plan, mutation{
+					Method:      "DELETE",
+					Path:        fmt.Sprintf("/orgs/%s/teams/%s/memberships/%s", org, slug, handle),
+					Description: fmt.Sprintf("remove %s from %s", handle, team),
+					Destructive: true,
+				})
+			}
+		}
+
+		granted := map[string]string{}
+		if exists {
+			for page := 1; ; page++ {
+				var teamRepos []struct {
+					FullName    string          `json:"full_name"`
+					Permissions map[string]bool `json:"permissions"`
+				}
+				if err := githubGet(fmt.Sprintf("/orgs/%s/teams/%s/repos?per_page=100&page=%d", org, slug, page), &teamRepos); err != nil {
+					return nil, fmt.Errorf("sync-teams: %s: %v", team, err)
+				}
+				for _, r := range teamRepos {
+					granted[strings.ToLower(r.FullName)] = highestPermission(r.Permissions)
+				}
+				if len(teamRepos) < 100 {
+					break
+				}
+			}
+		}
+
+		for _, repo := range repos[team] {
+			current := granted[strings.ToLower(repo)]
+			if current == permission {
+				continue
+			}
+			desc := fmt.Sprintf("give %s %s permission on %s", team, permission, repo)
+			if current != "" {
+				desc = fmt.Sprintf("change the permission of %s on %s from %s to %s", team, repo, current, permission)
+			}
+			plan = append(plan, mutation{
+				Method:      "PUT",
+				Path:        fmt.Sprintf("/orgs/%s/teams/%s/repos/%s", org, slug, repo),
+				Body:        map[string]string{"permission": permission},
+				Description: desc,
+				Destructive: current != "",
+			})
+		}
+	}
+	return plan, nil
+}
+
+// highestPermission returns the highest of the permissions of a team on a
+// repository, as returned by the GitHub API.
+func highestPermission(permissions map[string]bool) string {
+	for _, p := range []string{"admin", "maintain", "push", "triage", "pull"} {
+		if permissions[p] {
+			return p
+		}
+	}
+	return ""
+}
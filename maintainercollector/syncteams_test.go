@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPlanTeamSync(t *testing.T) {
+	withProjects(t, []string{"docker/cli", "docker/compose"}, Config{Teams: map[string][]string{
+		"cli":     {"docker/cli-maintainers"},
+		"compose": {"docker/compose-maintainers"},
+	}})
+	// the members of cli-maintainers span two pages
+	var firstPage []map[string]string
+	for i := 0; i < 100; i++ {
+		firstPage = append(firstPage, map[string]string{"login": fmt.Sprintf("user%d", i)})
+	}
+	gh := answerGitHub(map[string]interface{}{
+		"GET /orgs/docker/teams/cli-maintainers":                             map[string]string{},
+		"GET /orgs/docker/teams/cli-maintainers/members?per_page=100&page=1": firstPage,
+		"GET /orgs/docker/teams/cli-maintainers/members?per_page=100&page=2": []map[string]string{{"login": "alice"}},
+		"GET /orgs/docker/teams/cli-maintainers/repos?per_page=100&page=1": []map[string]interface{}{
+			{"full_name": "docker/cli", "permissions": map[string]bool{"push": true}},
+		},
+	})
+	useMocks(t, gh, nil, nil, nil)
+
+	m := Maintainers{Org: map[string]*Org{"cli": {People: []string{"alice"}}, "compose": {People: []string{"bob"}}}}
+	plan, err := planTeamSync(m, "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removals, creations := 0, 0
+	for _, mu := range plan {
+		switch {
+		case mu.Method == "PUT" && mu.Path == "/orgs/docker/teams/cli-maintainers/memberships/alice":
+			t.Error("alice, listed on the second page of members, is added again")
+		case mu.Method == "DELETE":
+			removals++
+		case mu.Method == "POST" && mu.Path == "/orgs/docker/teams":
+			creations++
+		}
+	}
+	if removals != 100 || creations != 1 {
+		t.Errorf("got %d removals and %d team creations, want the 100 users of the first page removed and compose-maintainers created: %+v", removals, creations, plan)
+	}
+
+	// a failure other than a missing team does not plan its creation
+	gh = answerGitHub(map[string]interface{}{
+		"GET /orgs/docker/teams/cli-maintainers": &githubError{Method: "GET", Path: "/orgs/docker/teams/cli-maintainers", StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"},
+	})
+	useMocks(t, gh, nil, nil, nil)
+	if _, err := planTeamSync(m, "push"); err == nil {
+		t.Error("got no error, want the failure to look up cli-maintainers")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tenants are independent collections hosted by a single serve process, each
+// with its own configuration file, schedule and output directory. They are
+// defined in a TOML file passed with serve -tenants:
+//
+//	[Tenant.docker]
+//	Config = "docker.toml"
+//	Interval = "1h"
+//
+//	[Tenant.moby]
+//	Config = "moby.toml"
+//	Interval = "6h"
+//	Output = "/srv/moby"
+//
+// Each tenant is served under its own prefix, e.g. /moby/MAINTAINERS. The
+// projects of a tenant are set with Projects in its configuration file.
+
+// Tenant is a collection hosted by the serve command.
+type Tenant struct {
+	// Config is the configuration file of the tenant, relative to the
+	// tenants file.
+	Config string
+
+	// Interval is the time between two regenerations, as accepted by
+	// time.ParseDuration. It defaults to the -interval flag of serve.
+	Interval string
+
+	// Output is a directory the combined MAINTAINERS file is also written
+	// to after each regeneration, if set.
+	Output string
+
+	// History is the history store of the tenant, if any, relative to the
+	// tenants file.
+	History string
+}
+
+// collectMu serializes the collections of all tenants, which share the
+// config and projects globals.
+var collectMu sync.Mutex
+
+// loadTenants reads the tenants file at path and returns a server for each
+// tenant, sorted by name.
+func loadTenants(path string, interval time.Duration) ([]*server, error) {
+	var f struct {
+		Tenant map[string]Tenant
+	}
+	if err := decodeFile(path, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Tenant) == 0 {
+		return nil, fmt.Errorf("%s: no tenants defined", path)
+	}
+
+	var servers []*server
+	for name, t := range f.Tenant {
+		if name == "" || strings.ContainsAny(name, "/?#") {
+			return nil, fmt.Errorf("%s: invalid tenant name %q", path, name)
+		}
+
+		s := &server{name: name, interval: interval, projects: projects, output: t.Output, history: t.History}
+		if s.history != "" && !filepath.IsAbs(s.history) {
+			s.history = filepath.Join(filepath.Dir(path), s.history)
+		}
+		if t.Interval != "" {
+			d, err := time.ParseDuration(t.Interval)
+			if err != nil {
+				return nil, fmt.Errorf("%s: Tenant.%s.Interval: %v", path, name, err)
+			}
+			s.interval = d
+		}
+
+		if t.Config == "" {
+			return nil, fmt.Errorf("%s: Tenant.%s: no Config", path, name)
+		}
+		c := t.Config
+		if !filepath.IsAbs(c) {
+			c = filepath.Join(filepath.Dir(path), c)
+		}
+		var err error
+		if s.config, err = readConfig(c); err != nil {
+			return nil, fmt.Errorf("tenant %s: %v", name, err)
+		}
+		if len(s.config.Projects) > 0 {
+			s.projects = s.config.Projects
+		}
+
+		servers = append(servers, s)
+	}
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].name < servers[j].name })
+	return servers, nil
+}
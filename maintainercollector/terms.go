@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Kinds of findings about term-limited memberships.
+const (
+	findingTermExpired  = "expired term"
+	findingTermExpiring = "expiring term"
+)
+
+// defaultTermNotice is how long before its end a term is reported as
+// expiring, unless TermNoticeDays is set in the configuration.
+const defaultTermNotice = 30 * 24 * time.Hour
+
+// Term is the period (YYYY-MM-DD dates, both optional) during which a person
+// listed in an Org section holds the membership, e.g. for term-limited
+// curators:
+//
+//	[Org.Curators]
+//		people = ["alice"]
+//		[Org.Curators.terms.alice]
+//			since = "2024-01-01"
+//			until = "2025-12-31"
+type Term struct {
+	Since string `toml:"since,omitempty" json:"since,omitempty"`
+	Until string `toml:"until,omitempty" json:"until,omitempty"`
+}
+
+// active reports whether the term includes the day of now. Invalid dates
+// are ignored.
+func (t Term) active(now time.Time) bool {
+	today := now.Format("2006-01-02")
+	if t.Since != "" && validDate(t.Since) && today < t.Since {
+		return false
+	}
+	if t.Until != "" && validDate(t.Until) && today > t.Until {
+		return false
+	}
+	return true
+}
+
+func validDate(date string) bool {
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+// term returns the term of nick in o, if any.
+func (o *Org) term(nick string) (Term, bool) {
+	for n, t := range o.Terms {
+		if strings.EqualFold(n, nick) {
+			return t, true
+		}
+	}
+	return Term{}, false
+}
+
+// activeMembers returns the people of the Org section whose term, if any,
+// includes now. Invalid term dates are logged.
+func activeMembers(project, section string, o *Org, now time.Time) []string {
+	for nick, t := range o.Terms {
+		for _, date := range []string{t.Since, t.Until} {
+			if date != "" && !validDate(date) {
+				logrus.Warnf("%s: invalid term date %q for %s in %s", project, date, nick, section)
+			}
+		}
+	}
+
+	var active []string
+	for _, nick := range o.People {
+		if t, ok := o.term(nick); ok && !t.active(now) {
+			if t.Until != "" && now.Format("2006-01-02") > t.Until {
+				logrus.Infof("%s: leaving out %s from %s, their term ended on %s", project, nick, section, t.Until)
+			} else {
+				logrus.Infof("%s: leaving out %s from %s, their term starts on %s", project, nick, section, t.Since)
+			}
+			continue
+		}
+		active = append(active, nick)
+	}
+	return active
+}
+
+// sourceSections returns the Org sections of the MAINTAINERS file of a
+// project holding people.
+func sourceSections(o Organization) map[string]*Org {
+	sections := map[string]*Org{}
+	for name, section := range map[string]*Org{
+		"Maintainers":      o.Maintainers,
+		"Core maintainers": o.CoreMaintainers,
+		"Docs maintainers": o.DocsMaintainers,
+		"Curators":         o.Curators,
+	} {
+		if section != nil {
+			sections[name] = section
+		}
+	}
+	return sections
+}
+
+// termFindings reports the people still listed in a section after the end
+// of their term, and those whose term ends within the notice period.
+func termFindings(c *collection, now time.Time) []finding {
+	notice := defaultTermNotice
+	if config.TermNoticeDays > 0 {
+		notice = time.Duration(config.TermNoticeDays) * 24 * time.Hour
+	}
+	soon := now.Add(notice).Format("2006-01-02")
+	today := now.Format("2006-01-02")
+
+	var findings []finding
+	for _, s := range c.Sources {
+		for name, o := range sourceSections(s.File.Organization) {
+			for _, nick := range o.People {
+				nick = strings.ToLower(nick)
+				t, ok := o.term(nick)
+				if !ok || t.Until == "" || !validDate(t.Until) {
+					continue
+				}
+				switch {
+				case t.Until < today:
+					findings = append(findings, finding{Kind: findingTermExpired, Project: s.Project,
+						Message: fmt.Sprintf("the %s term of %s ended on %s, remove them or renew the term", name, nick, t.Until)})
+				case t.Until <= soon:
+					findings = append(findings, finding{Kind: findingTermExpiring, Project: s.Project,
+						Message: fmt.Sprintf("the %s term of %s ends on %s", name, nick, t.Until)})
+				}
+			}
+		}
+	}
+	return findings
+}
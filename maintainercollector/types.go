@@ -1,28 +1,70 @@
 package main
 
+import "sort"
+
 // Maintainers defines the struct for a MAINTAINERS file
 type Maintainers struct {
-	Rules  map[string]Rule
-	Roles  map[string]Role
-	Org    map[string]*Org
-	People map[string]Person
+	Rules  map[string]Rule              `json:",omitempty"`
+	Roles  map[string]Role              `json:",omitempty"`
+	Org    map[string]*Org              `json:",omitempty"`
+	People map[string]Person            `json:",omitempty"`
+	Ladder map[string]map[string]Ladder `json:",omitempty"`
+
+	// Components maps each Org section to the components of the project,
+	// keyed by name. See Component.
+	Components map[string]map[string]*Component `json:",omitempty"`
+
+	Committees    map[string]*Group `json:",omitempty"`
+	WorkingGroups map[string]*Group `json:",omitempty"`
+
+	// Findings is only set in the internal view served by the serve command.
+	Findings []finding `toml:"-" json:",omitempty"`
+}
+
+// specialOrgs are the Org sections of the combined file that are not projects.
+var specialOrgs = map[string]bool{"Curators": true, "Docs maintainers": true}
+
+// Projects returns the sorted names of the projects in the Org section.
+func (m Maintainers) Projects() []string {
+	var projects []string
+	for name := range m.Org {
+		if !specialOrgs[name] {
+			projects = append(projects, name)
+		}
+	}
+	sort.Strings(projects)
+	return projects
 }
 
 // Rule is a project rule
 type Rule struct {
-	Title string `toml:"title,omitempty"`
-	Text  string `toml:"text,omitempty"`
+	Title string `toml:"title,omitempty" json:"title,omitempty"`
+	Text  string `toml:"text,omitempty" json:"text,omitempty"`
 }
 
 // Role is a project role
 type Role struct {
-	Person string `toml:"person,omitempty"`
-	Text   string `toml:"text,omitempty"`
+	Person string   `toml:"person,omitempty" json:"person,omitempty"`
+	People []string `toml:"people,omitempty" json:"people,omitempty"`
+	Text   string   `toml:"text,omitempty" json:"text,omitempty"`
 }
 
 // Org defines the organization within a project
 type Org struct {
+	Title  string `toml:"title,omitempty" json:"title,omitempty"`
 	People []string
+
+	// Terms limits the membership of people to a period, see Term. People
+	// outside their term are left out of the combined file.
+	Terms map[string]Term `toml:"terms,omitempty" json:"terms,omitempty"`
+}
+
+// Group is a committee or working group spanning projects
+type Group struct {
+	Title   string `toml:"title,omitempty" json:"title,omitempty"`
+	Charter string `toml:"charter,omitempty" json:"charter,omitempty"`
+	Link    string `toml:"link,omitempty" json:"link,omitempty"`
+	People  []string
 }
 
 // Person member of the project
@@ -30,6 +72,33 @@ type Person struct {
 	Name   string
 	Email  string
 	GitHub string
+
+	// Company is the employer of the person, used to check the company
+	// diversity of projects. It is completed from the GitHub profile.
+	Company string `toml:",omitempty" json:",omitempty"`
+
+	// AutoGenerated is set on entries synthesized from GitHub for people
+	// listed without one, see fallback.go.
+	AutoGenerated bool `toml:",omitempty" json:",omitempty"`
+}
+
+// Ladder records the dates (YYYY-MM-DD) at which a person was promoted to
+// each step of a project's contributor ladder.
+type Ladder struct {
+	Contributor string `toml:"contributor,omitempty" json:"contributor,omitempty"`
+	Reviewer    string `toml:"reviewer,omitempty" json:"reviewer,omitempty"`
+	Maintainer  string `toml:"maintainer,omitempty" json:"maintainer,omitempty"`
+}
+
+// Component is a part of a project, owned by some of its people. Paths maps
+// the globs of the files of the component, relative to the root of the
+// repository, to their weight: the owners of the component weighing the most
+// for a changed file are its first reviewers. In globs, "**" matches any
+// number of directories.
+type Component struct {
+	Title  string `toml:"title,omitempty" json:"title,omitempty"`
+	People []string
+	Paths  map[string]int `toml:"paths" json:"paths"`
 }
 
 // MaintainersDepreciated is an old struct for compatibility
@@ -39,6 +108,8 @@ type MaintainersDepreciated struct {
 	Rules        map[string]Rule
 	Organization Organization `toml:"Org"`
 	People       map[string]Person
+	Ladder       map[string]Ladder
+	Components   map[string]*Component
 }
 
 // Organization defines the project's organization
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// version is the version of the collector, set at build time with
+// -ldflags "-X main.version=...".
+var version = "dev"
+
+// contactURL is where to learn about the collector, sent in the User-Agent.
+const contactURL = "https://github.com/docker/opensource"
+
+// runIDHeader is the header carrying the identifier given with -run-id.
+const runIDHeader = "X-Maintainercollector-Run-Id"
+
+// runID identifies the run, given with -run-id. It is also the identifier
+// of the snapshot recorded in the history store, if any.
+var runID string
+
+// defaultUserAgent is the User-Agent sent unless -user-agent is given.
+func defaultUserAgent() string {
+	return fmt.Sprintf("maintainercollector/%s (+%s)", version, contactURL)
+}
+
+// tagger is an http.RoundTripper setting the User-Agent and, if any, the run
+// identifier header of every request, so that GitHub and proxies can
+// identify the traffic of the collector.
+type tagger struct {
+	userAgent string
+	runID     string
+	next      http.RoundTripper
+}
+
+func (t *tagger) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	if t.runID != "" {
+		req.Header.Set(runIDHeader, t.runID)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// tagClient makes client send the User-Agent and run identifier.
+func tagClient(client *http.Client, userAgent, runID string) {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &tagger{userAgent: userAgent, runID: runID, next: next}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationRules are the extra rules a project declares in the Validation
+// section of the configuration.
+type ValidationRules struct {
+	// MinMaintainers is the minimum number of maintainers.
+	MinMaintainers int
+
+	// RequiredRoles are the roles of the Org section of the MAINTAINERS
+	// file that must be filled: "bdfl", "Chief Architect", "Chief
+	// Maintainer" or "Community Manager".
+	RequiredRoles []string
+
+	// MinCompanies is the minimum number of distinct companies employing
+	// the maintainers. The company of people is their Company in People,
+	// or the one of their GitHub profile with -enrich-profiles.
+	MinCompanies int
+}
+
+// check validates the rules themselves.
+func (r ValidationRules) check() error {
+	if r.MinMaintainers < 0 || r.MinCompanies < 0 {
+		return fmt.Errorf("MinMaintainers and MinCompanies cannot be negative")
+	}
+	for _, role := range r.RequiredRoles {
+		if _, ok := organizationRole(Organization{}, role); !ok {
+			return fmt.Errorf("unknown role %q in RequiredRoles", role)
+		}
+	}
+	return nil
+}
+
+// validationRules returns the rules of a project: its own, those of its
+// Org section if it is aliased, or the default ones.
+func validationRules(project string) (ValidationRules, bool) {
+	for _, name := range []string{project, sectionName(project), "default"} {
+		if r, ok := config.Validation[name]; ok {
+			return r, true
+		}
+	}
+	return ValidationRules{}, false
+}
+
+// validateProjects checks the validation rules of each project loaded in c,
+// and returns the violations.
+func validateProjects(c *collection) []finding {
+	var findings []finding
+	for _, s := range c.Sources {
+		rules, ok := validationRules(s.Project)
+		if !ok {
+			continue
+		}
+		add := func(format string, args ...interface{}) {
+			findings = append(findings, finding{Kind: findingRule, Project: s.Project, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if len(s.Maintainers) < rules.MinMaintainers {
+			add("%d maintainers, at least %d required", len(s.Maintainers), rules.MinMaintainers)
+		}
+
+		for _, role := range rules.RequiredRoles {
+			if holder, _ := organizationRole(s.File.Organization, role); holder == "" {
+				add("no %s", role)
+			}
+		}
+
+		if rules.MinCompanies > 0 {
+			var companies, unknown []string
+			for _, nick := range s.Maintainers {
+				company := normalizeCompany(c.Maintainers.People[nick].Company)
+				if company == "" {
+					unknown = append(unknown, nick)
+				} else if !containsFold(companies, company) {
+					companies = append(companies, company)
+				}
+			}
+			if len(companies) < rules.MinCompanies {
+				msg := fmt.Sprintf("maintainers from %d companies, at least %d required", len(companies), rules.MinCompanies)
+				if len(unknown) > 0 {
+					msg += fmt.Sprintf(" (unknown company: %s)", strings.Join(unknown, ", "))
+				}
+				add("%s", msg)
+			}
+		}
+	}
+	return findings
+}
+
+// organizationRole returns the holder of a role of the Org section of a
+// MAINTAINERS file, and whether the role is known.
+func organizationRole(o Organization, role string) (string, bool) {
+	switch strings.ToLower(role) {
+	case "bdfl":
+		return o.BDFL, true
+	case "chief architect":
+		return o.ChiefArchitect, true
+	case "chief maintainer":
+		return o.ChiefMaintainer, true
+	case "community manager":
+		return o.CommunityManager, true
+	default:
+		return "", false
+	}
+}
+
+// normalizeCompany returns the comparable form of a company name, as
+// written on GitHub profiles: "@Docker " is "docker".
+func normalizeCompany(company string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(company), "@"))
+}
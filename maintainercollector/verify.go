@@ -0,0 +1,290 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// contactVerification is a verification email sent to a maintainer.
+type contactVerification struct {
+	Nick      string    `json:"nick"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	Sent      time.Time `json:"sent"`
+	Confirmed time.Time `json:"confirmed,omitempty"`
+}
+
+// verificationStore keeps the verifications of the current campaign in a
+// JSON file, shared by the verify-contacts command and the serve command.
+type verificationStore struct {
+	mu   sync.Mutex
+	path string
+
+	Started       time.Time              `json:"started"`
+	Verifications []*contactVerification `json:"verifications"`
+}
+
+// loadVerifications reads the verification store at path. A missing file is
+// an empty store.
+func loadVerifications(path string) (*verificationStore, error) {
+	s := &verificationStore{path: path}
+	b, err := files.Load(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
+
+// save writes the store to its file. The caller must hold s.mu.
+func (s *verificationStore) save() error {
+	b, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	return files.Save(s.path, append(b, '\n'), 0600)
+}
+
+// verifyTemplate is the page of a verification link. Opening the link does
+// not confirm the address, which mail scanners following links would do:
+// the person confirms it with the button, which posts the token back.
+var verifyTemplate = template.Must(template.New("verify").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Verify your maintainer contact address</title>
+</head>
+<body>
+{{if .Confirmed.IsZero}}
+<p>Hi {{.Nick}}, please confirm that {{.Email}} is still your contact address as a maintainer.</p>
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Confirm {{.Email}}</button>
+</form>
+{{else}}
+<p>Thank you {{.Nick}}, {{.Email}} is verified.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// refresh reloads the store from its file, as a campaign may have been
+// started since it was loaded. The caller must hold s.mu.
+func (s *verificationStore) refresh() error {
+	fresh, err := loadVerifications(s.path)
+	if err != nil {
+		return err
+	}
+	s.Started, s.Verifications = fresh.Started, fresh.Verifications
+	return nil
+}
+
+// lookup returns a copy of the verification with the given token.
+func (s *verificationStore) lookup(token string) (contactVerification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return contactVerification{}, err
+	}
+	for _, v := range s.Verifications {
+		if v.Token == token {
+			return *v, nil
+		}
+	}
+	return contactVerification{}, fmt.Errorf("unknown token")
+}
+
+// confirm marks the verification with the given token as confirmed.
+func (s *verificationStore) confirm(token string) (*contactVerification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	for _, v := range s.Verifications {
+		if v.Token == token {
+			if v.Confirmed.IsZero() {
+				v.Confirmed = time.Now().UTC()
+				if err := s.save(); err != nil {
+					return nil, err
+				}
+			}
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown token")
+}
+
+// serveVerify handles the verification links sent by verify-contacts: GET
+// requests show the confirmation page of the token, and POST requests from
+// that page confirm it.
+func (s *verificationStore) serveVerify(w http.ResponseWriter, r *http.Request) {
+	var v contactVerification
+	var err error
+	switch r.Method {
+	case "GET", "HEAD":
+		v, err = s.lookup(r.URL.Query().Get("token"))
+	case "POST":
+		var confirmed *contactVerification
+		if confirmed, err = s.confirm(r.FormValue("token")); err == nil {
+			v = *confirmed
+			logrus.Infof("verified contact %s of %s", v.Email, v.Nick)
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid or expired verification link", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err := verifyTemplate.Execute(w, v); err != nil {
+		logrus.Errorf("verify: %v", err)
+	}
+}
+
+// verifyContactsCmd implements the verify-contacts command.
+func verifyContactsCmd(args []string) error {
+	fs := flag.NewFlagSet("verify-contacts", flag.ExitOnError)
+	store := fs.String("store", "verifications.json", "file recording the verifications")
+	smtpAddr := fs.String("smtp", "localhost:25", "SMTP server to send the emails through; SMTP_USERNAME and SMTP_PASSWORD are used to authenticate, if set")
+	from := fs.String("from", "", "sender address of the emails")
+	baseURL := fs.String("url", "", "base URL of the serve command receiving the verification links")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: verify-contacts [options] send|report\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	s, err := loadVerifications(*store)
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "send":
+		if *from == "" || *baseURL == "" {
+			return fmt.Errorf("verify-contacts: send requires -from and -url")
+		}
+		return sendVerifications(s, collectMaintainers(), *smtpAddr, *from, strings.TrimRight(*baseURL, "/"))
+	case "report":
+		return reportVerifications(s)
+	default:
+		fs.Usage()
+		return fmt.Errorf("verify-contacts: expected send or report")
+	}
+}
+
+// sendVerifications starts a new campaign, emailing a verification link to
+// every person listed in an Org section.
+func sendVerifications(s *verificationStore, m Maintainers, smtpAddr, from, baseURL string) error {
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USERNAME"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), strings.Split(smtpAddr, ":")[0])
+	}
+
+	listed := map[string]bool{}
+	for _, o := range m.Org {
+		for _, nick := range o.People {
+			listed[nick] = true
+		}
+	}
+	var nicks []string
+	for nick := range listed {
+		nicks = append(nicks, nick)
+	}
+	sort.Strings(nicks)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Started = time.Now().UTC()
+	s.Verifications = nil
+	if err := s.save(); err != nil {
+		return err
+	}
+
+	for _, nick := range nicks {
+		person, ok := m.People[nick]
+		if !ok || person.Email == "" {
+			logrus.Warnf("%s has no email address", nick)
+			continue
+		}
+
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return err
+		}
+		v := &contactVerification{Nick: nick, Email: person.Email, Token: hex.EncodeToString(token), Sent: time.Now().UTC()}
+
+		// the token is saved before the email is sent, so that a failure
+		// of the campaign cannot leave links that do not work
+		s.Verifications = append(s.Verifications, v)
+		if err := s.save(); err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Please verify your maintainer contact address\r\n\r\n"+
+			"Hi %s,\r\n\r\nYou are listed as a maintainer with this email address in the Docker projects MAINTAINERS file.\r\n"+
+			"Please confirm it is still correct by opening:\r\n\r\n    %s/verify?token=%s\r\n\r\n"+
+			"If it is not, please open a pull request updating your entry.\r\n",
+			from, v.Email, person.Name, baseURL, v.Token)
+		if err := smtp.SendMail(smtpAddr, auth, from, []string{v.Email}, []byte(msg)); err != nil {
+			logrus.Errorf("sending verification to %s failed: %v", v.Email, err)
+			s.Verifications = s.Verifications[:len(s.Verifications)-1]
+			if err := s.save(); err != nil {
+				return err
+			}
+			continue
+		}
+		logrus.Infof("sent verification to %s (%s)", nick, v.Email)
+	}
+	return nil
+}
+
+// reportVerifications prints the contacts that were not verified yet.
+func reportVerifications(s *verificationStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	confirmed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MAINTAINER\tEMAIL\tSENT")
+	for _, v := range s.Verifications {
+		if !v.Confirmed.IsZero() {
+			confirmed++
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Nick, v.Email, v.Sent.Format("2006-01-02"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d of %d contacts verified since %s\n", confirmed, len(s.Verifications), s.Started.Format("2006-01-02"))
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerificationStoreConfirm(t *testing.T) {
+	saved := map[string][]byte{}
+	useMocks(t, nil, nil, nil, memoryStore(saved))
+
+	s, err := loadVerifications("verifications.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Started = time.Now().UTC()
+	s.Verifications = []*contactVerification{{Nick: "alice", Email: "alice@example.com", Token: "secret", Sent: s.Started}}
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.confirm("guess"); err == nil {
+		t.Error("confirmed an unknown token")
+	}
+	v, err := s.confirm("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Nick != "alice" || v.Confirmed.IsZero() {
+		t.Errorf("got %+v, want the confirmed verification of alice", v)
+	}
+
+	var confirmed verificationStore
+	if err := json.Unmarshal(saved["verifications.json"], &confirmed); err != nil {
+		t.Fatal(err)
+	}
+	if len(confirmed.Verifications) != 1 || confirmed.Verifications[0].Confirmed.IsZero() {
+		t.Errorf("the confirmation was not saved: %s", saved["verifications.json"])
+	}
+}
+
+// TestServeVerify checks that opening a verification link does not confirm
+// the address, and that the button of the page does.
+func TestServeVerify(t *testing.T) {
+	useMocks(t, nil, nil, nil, memoryStore(map[string][]byte{}))
+	s, err := loadVerifications("verifications.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Verifications = []*contactVerification{{Nick: "alice", Email: "alice@example.com", Token: "secret"}}
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	s.serveVerify(w, httptest.NewRequest("GET", "/verify?token=secret", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `method="post"`) {
+		t.Errorf("GET: got %d %s, want the confirmation form", w.Code, w.Body)
+	}
+	if v, _ := s.lookup("secret"); !v.Confirmed.IsZero() {
+		t.Error("GET confirmed the address")
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/verify", strings.NewReader(url.Values{"token": {"secret"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.serveVerify(w, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "is verified") {
+		t.Errorf("POST: got %d %s, want the address verified", w.Code, w.Body)
+	}
+	if v, _ := s.lookup("secret"); v.Confirmed.IsZero() {
+		t.Error("POST did not confirm the address")
+	}
+
+	w = httptest.NewRecorder()
+	s.serveVerify(w, httptest.NewRequest("GET", "/verify?token=guess", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown token: got %d, want 404", w.Code)
+	}
+}
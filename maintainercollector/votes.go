@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// vote is the tally of the reviews on a pull request changing MAINTAINERS.
+type vote struct {
+	Project   string
+	Number    int
+	Title     string
+	Approvals approvals
+	Approved  []string
+	Rejected  []string
+}
+
+// Passed reports whether the change met the required approval quorum.
+func (v vote) Passed() bool {
+	passed, _ := v.Approvals.tally(v.Approved)
+	return passed
+}
+
+// votesCmd implements the votes command.
+func votesCmd(args []string) error {
+	fs := flag.NewFlagSet("votes", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: votes [project...]\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = projects
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tPR\tTITLE\tAPPROVALS\tREQUIRED\tREJECTIONS\tQUORUM")
+	for _, p := range targets {
+		org, project := getProjectOrg(p)
+		votes, err := getVotes(org, project)
+		if err != nil {
+			logrus.Errorf("%s/%s: %v", org, project, err)
+			continue
+		}
+		for _, v := range votes {
+			quorum := "no"
+			if v.Passed() {
+				quorum = "yes"
+			}
+			fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%d/%d\t%s\t%s\n", v.Project, v.Number, v.Title,
+				strings.Join(v.Approved, ", "), v.Approvals.Required, len(v.Approvals.Maintainers),
+				strings.Join(v.Rejected, ", "), quorum)
+		}
+	}
+	return w.Flush()
+}
+
+// getVotes tallies the reviews of every open pull request of a project that
+// modifies its MAINTAINERS file. Only reviews by current maintainers and by
+// holders of the roles required by the voting rule count as votes; for each
+// of them the latest approval or change request wins.
+func getVotes(org, project string) ([]vote, error) {
+	file, err := getRepoFile(org, project, "MAINTAINERS")
+	if err != nil {
+		return nil, err
+	}
+	var current MaintainersDepreciated
+	if _, err := toml.Decode(string(file.Content), &current); err != nil {
+		return nil, fmt.Errorf("parsing MAINTAINERS file failed: %v", err)
+	}
+	_, people := maintainersSection(current)
+
+	// reviews are made by GitHub handles, which may differ from the nicks
+	required := requiredApprovals(project, people)
+	voters := voterNicks(current, people)
+	for _, holders := range required.Roles {
+		for _, h := range holders {
+			voters[strings.ToLower(h)] = strings.ToLower(h)
+		}
+	}
+
+	var pulls []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/pulls?state=open&per_page=100", org, project), &pulls); err != nil {
+		return nil, err
+	}
+
+	var votes []vote
+	for _, pr := range pulls {
+		var files []struct {
+			Filename string `json:"filename"`
+		}
+		if err := githubGet(fmt.Sprintf("/repos/%s/%s/pulls/%d/files?per_page=100", org, project, pr.Number), &files); err != nil {
+			return nil, err
+		}
+		modifies := false
+		for _, f := range files {
+			if f.Filename == "MAINTAINERS" {
+				modifies = true
+			}
+		}
+		if !modifies {
+			continue
+		}
+
+		var reviews []struct {
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			State string `json:"state"`
+		}
+		if err := githubGet(fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews?per_page=100", org, project, pr.Number), &reviews); err != nil {
+			return nil, err
+		}
+
+		// reviews are returned in chronological order
+		latest := map[string]string{}
+		for _, r := range reviews {
+			nick, ok := voters[strings.ToLower(r.User.Login)]
+			if !ok {
+				continue
+			}
+			switch r.State {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				latest[nick] = r.State
+			}
+		}
+
+		v := vote{Project: project, Number: pr.Number, Title: pr.Title, Approvals: required}
+		for nick, state := range latest {
+			switch state {
+			case "APPROVED":
+				v.Approved = append(v.Approved, nick)
+			case "CHANGES_REQUESTED":
+				v.Rejected = append(v.Rejected, nick)
+			}
+		}
+		sort.Strings(v.Approved)
+		sort.Strings(v.Rejected)
+		votes = append(votes, v)
+	}
+
+	return votes, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Sirupsen/logrus"
+)
+
+// VotingRule describes the approvals needed to change the maintainers of a
+// project.
+type VotingRule struct {
+	// Threshold is the share of current maintainers that must approve:
+	// "majority", "all", a fraction such as "2/3" or a ratio such as "0.66".
+	Threshold string `toml:"threshold"`
+	// Roles lists the project roles (see [Roles]) that must approve, in
+	// addition to the threshold. A role held by several people is satisfied
+	// by the approval of any of them.
+	Roles []string `toml:"roles"`
+}
+
+// required returns the number of approvals the rule needs out of n
+// maintainers, along with how it was computed.
+func (r VotingRule) required(n int) (int, string, error) {
+	switch t := strings.TrimSpace(r.Threshold); {
+	case t == "majority":
+		return n/2 + 1, fmt.Sprintf("floor(%d / 2) + 1 = %d", n, n/2+1), nil
+	case t == "all":
+		return n, fmt.Sprintf("all %d", n), nil
+	case strings.Contains(t, "/"):
+		parts := strings.SplitN(t, "/", 2)
+		a, errA := strconv.Atoi(strings.TrimSpace(parts[0]))
+		b, errB := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if errA != nil || errB != nil || a <= 0 || b <= 0 || a > b {
+			return 0, "", fmt.Errorf("invalid threshold %q", r.Threshold)
+		}
+		required := (a*n + b - 1) / b
+		return required, fmt.Sprintf("ceil(%d/%d × %d) = %d", a, b, n, required), nil
+	default:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return 0, "", fmt.Errorf("invalid threshold %q", r.Threshold)
+		}
+		// guard against 0.66 × 50 = 33.000000000000004
+		required := int(math.Ceil(f*float64(n) - 1e-9))
+		return required, fmt.Sprintf("ceil(%s × %d) = %d", t, n, required), nil
+	}
+}
+
+// approvals describes the votes a change to a project's maintainers needs.
+type approvals struct {
+	Rule        VotingRule
+	Maintainers []string
+	Required    int
+	// Math explains how Required was computed.
+	Math string
+	// Roles maps each required role to the people holding it.
+	Roles map[string][]string
+}
+
+func (a approvals) String() string {
+	var parts []string
+	for _, role := range a.Rule.Roles {
+		holders := make([]string, len(a.Roles[role]))
+		for i, h := range a.Roles[role] {
+			holders[i] = "@" + h
+		}
+		parts = append(parts, fmt.Sprintf("the %s (%s)", role, strings.Join(holders, " or ")))
+	}
+	parts = append(parts, fmt.Sprintf("at least %d of the %d current maintainers", a.Required, len(a.Maintainers)))
+	return "approval from " + strings.Join(parts, " and ")
+}
+
+// tally evaluates the given approvers against the rule. It reports whether
+// the rule is met, with the reasoning behind the result.
+func (a approvals) tally(approvers []string) (bool, []string) {
+	var counted, ignored []string
+	for _, v := range approvers {
+		if containsFold(a.Maintainers, v) {
+			counted = append(counted, v)
+		} else if !a.holdsRole(v) {
+			ignored = append(ignored, v)
+		}
+	}
+	sort.Strings(counted)
+
+	passed := len(counted) >= a.Required
+	reasons := []string{
+		fmt.Sprintf("maintainers: %d", len(a.Maintainers)),
+		fmt.Sprintf("threshold: %s, required approvals: %s", a.Rule.Threshold, a.Math),
+		fmt.Sprintf("maintainer approvals: %d (%s)", len(counted), strings.Join(counted, ", ")),
+	}
+	if len(ignored) > 0 {
+		reasons = append(reasons, fmt.Sprintf("not counted (not maintainers): %s", strings.Join(ignored, ", ")))
+	}
+
+	for _, role := range a.Rule.Roles {
+		approved := false
+		for _, h := range a.Roles[role] {
+			if containsFold(approvers, h) {
+				approved = true
+			}
+		}
+		if !approved {
+			passed = false
+		}
+		reasons = append(reasons, fmt.Sprintf("role %s (%s): approved=%t", role, strings.Join(a.Roles[role], ", "), approved))
+	}
+
+	return passed, reasons
+}
+
+// holdsRole reports whether nick holds one of the roles required by the rule.
+func (a approvals) holdsRole(nick string) bool {
+	for _, holders := range a.Roles {
+		if containsFold(holders, nick) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredApprovals computes the approvals the voting rule of a project needs
+// to change the given list of maintainers.
+func requiredApprovals(project string, maintainers []string) approvals {
+	rule, ok := config.Voting[project]
+	if !ok {
+		rule = config.Voting["default"]
+	}
+
+	required, m, err := rule.required(len(maintainers))
+	if err != nil {
+		logrus.Fatalf("%s: %v", project, err)
+	}
+
+	a := approvals{
+		Rule:        rule,
+		Maintainers: maintainers,
+		Required:    required,
+		Math:        m,
+		Roles:       map[string][]string{},
+	}
+
+	roles := projectRoles()
+	for _, name := range rule.Roles {
+		for key, role := range roles {
+			if strings.EqualFold(key, name) {
+				if role.Person != "" {
+					a.Roles[name] = append(a.Roles[name], role.Person)
+				}
+				a.Roles[name] = append(a.Roles[name], role.People...)
+			}
+		}
+	}
+
+	return a
+}
+
+// projectRoles returns the project roles defined in roles.toml.
+func projectRoles() map[string]Role {
+	var r struct {
+		Roles map[string]Role
+	}
+	if _, err := toml.Decode(roles, &r); err != nil {
+		logrus.Fatalf("parsing roles failed: %v", err)
+	}
+	return r.Roles
+}
+
+// voterNicks maps the lowercased nicks and GitHub handles of the maintainers
+// of a project to their nick, so that reviewers and approvers can be matched
+// against the roster.
+func voterNicks(m MaintainersDepreciated, maintainers []string) map[string]string {
+	voters := map[string]string{}
+	for _, nick := range maintainers {
+		voters[strings.ToLower(nick)] = strings.ToLower(nick)
+	}
+	for nick, person := range m.People {
+		if _, ok := voters[strings.ToLower(nick)]; ok && person.GitHub != "" {
+			voters[strings.ToLower(person.GitHub)] = strings.ToLower(nick)
+		}
+	}
+	return voters
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/opensource/maintainercollector/clients"
+)
+
+// Webhook is a downstream consumer notified of the changes to the
+// maintainers after each regeneration of the serve command.
+type Webhook struct {
+	URL string
+
+	// Secret is the key of the HMAC-SHA256 signature of the payload, sent
+	// as "sha256=<hex>" in the X-Maintainers-Signature header. Webhooks
+	// without Secret are unsigned, and a warning is logged when loading the
+	// configuration.
+	Secret string
+
+	// Digest batches the changes into one notification per period:
+	// "hourly", "daily" or a duration as accepted by time.ParseDuration.
+	// The notification holds the net changes over the period, so that
+	// changes reverted within it are not notified at all. Without Digest,
+	// every regeneration changing the maintainers is notified.
+	Digest string
+
+	// DedupWindow drops notifications identical to one sent to the webhook
+	// within this duration, such as the same edit flapping upstream. It
+	// defaults to the Digest period.
+	DedupWindow string
+}
+
+// digestPeriod returns the period of the digests of h, 0 without Digest.
+func (h Webhook) digestPeriod() (time.Duration, error) {
+	switch h.Digest {
+	case "":
+		return 0, nil
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	}
+	return time.ParseDuration(h.Digest)
+}
+
+// dedupWindow returns the DedupWindow of h, or its digest period.
+func (h Webhook) dedupWindow() (time.Duration, error) {
+	if h.DedupWindow == "" {
+		return h.digestPeriod()
+	}
+	return time.ParseDuration(h.DedupWindow)
+}
+
+// rosterDelta is the payload of the webhooks: the changes between two
+// regenerations.
+type rosterDelta struct {
+	Tenant    string                   `json:"tenant,omitempty"`
+	Generated time.Time                `json:"generated"`
+	Since     *time.Time               `json:"since,omitempty"`
+	Projects  map[string]*membersDelta `json:"projects,omitempty"`
+	People    *peopleDelta             `json:"people,omitempty"`
+}
+
+// membersDelta lists the people added to and removed from a project.
+type membersDelta struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// peopleDelta lists the People entries added, removed or changed.
+type peopleDelta struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// empty reports whether d has no changes.
+func (d rosterDelta) empty() bool {
+	return len(d.Projects) == 0 && d.People == nil
+}
+
+// diffMaintainers returns the changes from old to new.
+func diffMaintainers(old, new Maintainers) rosterDelta {
+	d := rosterDelta{Projects: map[string]*membersDelta{}}
+
+	names := map[string]bool{}
+	for name := range old.Org {
+		names[name] = true
+	}
+	for name := range new.Org {
+		names[name] = true
+	}
+	for name := range names {
+		var before, after []string
+		if o := old.Org[name]; o != nil {
+			before = o.People
+		}
+		if o := new.Org[name]; o != nil {
+			after = o.People
+		}
+		added, removed := diffNicks(before, after)
+		if len(added) > 0 || len(removed) > 0 {
+			d.Projects[name] = &membersDelta{Added: added, Removed: removed}
+		}
+	}
+
+	var people peopleDelta
+	for nick, p := range new.People {
+		if q, ok := old.People[nick]; !ok {
+			people.Added = append(people.Added, nick)
+		} else if p != q {
+			people.Changed = append(people.Changed, nick)
+		}
+	}
+	for nick := range old.People {
+		if _, ok := new.People[nick]; !ok {
+			people.Removed = append(people.Removed, nick)
+		}
+	}
+	sort.Strings(people.Added)
+	sort.Strings(people.Removed)
+	sort.Strings(people.Changed)
+	if len(people.Added) > 0 || len(people.Removed) > 0 || len(people.Changed) > 0 {
+		d.People = &people
+	}
+
+	return d
+}
+
+// diffNicks returns the sorted nicks of after missing from before, and of
+// before missing from after.
+func diffNicks(before, after []string) (added, removed []string) {
+	for _, n := range after {
+		if !containsFold(before, n) {
+			added = append(added, n)
+		}
+	}
+	for _, n := range before {
+		if !containsFold(after, n) {
+			removed = append(removed, n)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// webhookQueue holds the changes pending for a webhook, and the
+// notifications recently sent to it.
+type webhookQueue struct {
+	// base is the maintainers before the first pending change, nil if no
+	// change is pending.
+	base  *Maintainers
+	since time.Time
+
+	// sent maps the fingerprint of the notifications sent to the time they
+	// were sent.
+	sent map[string]time.Time
+}
+
+// notifyWebhooks queues the changes from old to new for every webhook, and
+// posts the net pending changes to those whose digest period elapsed.
+// queues holds the queue of each webhook, in order, and is extended as
+// needed. Failures are logged, and the changes stay pending, to be posted
+// again at the next call.
+func notifyWebhooks(hooks []Webhook, queues *[]*webhookQueue, tenant string, old, new Maintainers) {
+	now := time.Now().UTC()
+	changed := !diffMaintainers(old, new).empty()
+	for i, h := range hooks {
+		if i >= len(*queues) {
+			*queues = append(*queues, &webhookQueue{sent: map[string]time.Time{}})
+		}
+		q := (*queues)[i]
+
+		if q.base == nil {
+			if !changed {
+				continue
+			}
+			q.base, q.since = &old, now
+		}
+		period, _ := h.digestPeriod()
+		if now.Sub(q.since) < period {
+			continue
+		}
+
+		d := diffMaintainers(*q.base, new)
+		if d.empty() {
+			q.base = nil
+			logrus.Infof("webhook %s: changes since %s reverted, nothing to notify", h.URL, q.since.Format(time.RFC3339))
+			continue
+		}
+		window, _ := h.dedupWindow()
+		key := d.fingerprint()
+		for k, t := range q.sent {
+			if now.Sub(t) >= window {
+				delete(q.sent, k)
+			}
+		}
+		if t, ok := q.sent[key]; ok {
+			q.base = nil
+			logrus.Infof("webhook %s: same changes already notified at %s", h.URL, t.Format(time.RFC3339))
+			continue
+		}
+
+		d.Tenant, d.Generated = tenant, now
+		if period > 0 {
+			since := q.since
+			d.Since = &since
+		}
+		payload, err := json.Marshal(d)
+		if err != nil {
+			q.base = nil
+			logrus.Errorf("encoding webhook payload failed: %v", err)
+			continue
+		}
+		if err := notifications.Notify(h.URL, h.Secret, payload); err != nil {
+			logrus.Errorf("webhook %s: %v; retrying at the next regeneration", h.URL, err)
+			continue
+		}
+		q.base = nil
+		if window > 0 {
+			q.sent[key] = now
+		}
+	}
+}
+
+// fingerprint identifies the changes of d, whatever their time.
+func (d rosterDelta) fingerprint() string {
+	d.Tenant, d.Generated, d.Since = "", time.Time{}, nil
+	b, _ := json.Marshal(d)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// webhookClient sends the webhooks; a slow consumer must not hold up the
+// regenerations for long.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// notifier delivers the webhooks. See package clientstest for a mock.
+type notifier = clients.Notifier
+
+// notifications is the notifier used by serve.
+var notifications notifier = webhookNotifier{}
+
+// webhookNotifier is the notifier posting the webhooks with webhookClient.
+type webhookNotifier struct{}
+
+// Notify posts the payload to url, signed with secret if not empty.
+func (webhookNotifier) Notify(url string, secret string, payload []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set("X-Maintainers-Signature", "sha256="+signPayload(secret, payload))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST: %s", resp.Status)
+	}
+	return nil
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 of payload keyed by secret.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/docker/opensource/maintainercollector/clients/clientstest"
+)
+
+// roster returns the maintainers of a single project.
+func roster(project string, people ...string) Maintainers {
+	return Maintainers{Org: map[string]*Org{project: {People: people}}}
+}
+
+func TestNotifyWebhooks(t *testing.T) {
+	n := &clientstest.Notifier{}
+	useMocks(t, nil, nil, n, nil)
+	hooks := []Webhook{{URL: "https://example.com/hook", DedupWindow: "1h"}}
+	var queues []*webhookQueue
+
+	old, new := roster("cli", "alice"), roster("cli", "alice", "bob")
+	notifyWebhooks(hooks, &queues, "", old, new)
+	if len(n.NotifyCalls) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(n.NotifyCalls))
+	}
+	var d rosterDelta
+	if err := json.Unmarshal(n.NotifyCalls[0].Payload, &d); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]*membersDelta{"cli": {Added: []string{"bob"}}}; !reflect.DeepEqual(d.Projects, want) {
+		t.Errorf("got projects %+v, want %+v", d.Projects, want)
+	}
+
+	// the same change flapping within the dedup window is dropped
+	notifyWebhooks(hooks, &queues, "", new, old)
+	notifyWebhooks(hooks, &queues, "", old, new)
+	if len(n.NotifyCalls) != 2 {
+		t.Errorf("got %d notifications, want 2: the removal of bob and not its addition again", len(n.NotifyCalls))
+	}
+
+	// regenerations changing nothing are not notified
+	notifyWebhooks(hooks, &queues, "", new, new)
+	if len(n.NotifyCalls) != 2 {
+		t.Errorf("got %d notifications, want 2", len(n.NotifyCalls))
+	}
+}
+
+// TestNotifyWebhooksRetry checks that changes whose notification failed are
+// notified again at the next call.
+func TestNotifyWebhooksRetry(t *testing.T) {
+	n := failNotifications(errors.New("POST: 503 Service Unavailable"))
+	useMocks(t, nil, nil, n, nil)
+	hooks := []Webhook{{URL: "https://example.com/hook"}}
+	var queues []*webhookQueue
+
+	old, new := roster("cli", "alice"), roster("cli", "alice", "bob")
+	notifyWebhooks(hooks, &queues, "", old, new)
+	n.NotifyFunc = nil
+	notifyWebhooks(hooks, &queues, "", new, new)
+	if len(n.NotifyCalls) != 2 {
+		t.Fatalf("got %d notifications, want the failed one and its retry", len(n.NotifyCalls))
+	}
+	var d rosterDelta
+	if err := json.Unmarshal(n.NotifyCalls[1].Payload, &d); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]*membersDelta{"cli": {Added: []string{"bob"}}}; !reflect.DeepEqual(d.Projects, want) {
+		t.Errorf("retry: got projects %+v, want %+v", d.Projects, want)
+	}
+
+	notifyWebhooks(hooks, &queues, "", new, new)
+	if len(n.NotifyCalls) != 2 {
+		t.Errorf("got %d notifications after the retry succeeded, want 2", len(n.NotifyCalls))
+	}
+}